@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/acardace/contribution-report/internal/clients"
@@ -18,9 +19,21 @@ func main() {
 	associate := flag.String("associate", "", "Associate name from config file")
 	configFile := flag.String("config", "config.yaml", "Path to config file")
 	outputDir := flag.String("output", "reports", "Output directory for reports")
+	discoverFields := flag.Bool("discover-jira-fields", false, "List Jira fields that look like the story points, sprint and epic link fields and exit")
 
 	flag.Parse()
 
+	if *discoverFields {
+		cfg, err := config.Load(*configFile)
+		if err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+		if err := discoverJiraFields(cfg); err != nil {
+			log.Fatalf("Error discovering Jira fields: %v", err)
+		}
+		return
+	}
+
 	if *quarter == "" {
 		fmt.Println("Usage: contribution-report --quarter <Q1|Q2|Q3|Q4> [--associate <name>] [--year <year>] [--config <path>] [--output <dir>]")
 		fmt.Println("\nIf --associate is not specified, reports will be generated for all associates in the config file.")
@@ -59,6 +72,16 @@ func main() {
 		fmt.Printf("No associate specified - generating reports for all %d associates\n", len(associatesToProcess))
 	}
 
+	jiraClient, err := clients.NewJiraClient(cfg.Jira.URL, cfg.Jira.Token, jiraOptions(cfg))
+	if err != nil {
+		log.Fatalf("Error configuring Jira client: %v", err)
+	}
+
+	githubClient, err := clients.NewGitHubClient(cfg.GitHub.Token, githubOptions(cfg))
+	if err != nil {
+		log.Fatalf("Error configuring GitHub client: %v", err)
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(*outputDir, 0755); err != nil {
 		log.Fatalf("Error creating output directory: %v", err)
@@ -74,25 +97,36 @@ func main() {
 
 		// Fetch Jira data
 		fmt.Println("  Fetching Jira data...")
-		jiraClient := clients.NewJiraClient(cfg.Jira.URL, cfg.Jira.Token)
-		jiraIssues, err := jiraClient.FetchCompletedIssues(associateInfo.JiraUsername, startDate, endDate)
+		jiraData, err := jiraClient.FetchContributions(associateInfo.JiraUsername, startDate, endDate)
 		if err != nil {
 			log.Printf("  Warning: Error fetching Jira data for %s: %v", assocName, err)
 			continue
 		}
 
+		if jiraData.Incomplete() {
+			log.Printf("  Warning: Jira returned %d of %d matching issues for %s", len(jiraData.Issues), jiraData.ServerTotal, assocName)
+		}
+		if len(jiraData.UnparsedDates) > 0 {
+			log.Printf("  Warning: Could not parse Jira dates for %s: %s", assocName, strings.Join(jiraData.UnparsedDates, ", "))
+		}
+		for _, warning := range jiraData.Warnings {
+			log.Printf("  Warning: %s", warning)
+		}
+
 		// Fetch GitHub data
 		fmt.Println("  Fetching GitHub data...")
-		githubClient := clients.NewGitHubClient(cfg.GitHub.Token)
-		githubData, err := githubClient.FetchContributions(associateInfo.GitHubUsername, startDate, endDate)
+		githubData, err := githubClient.FetchContributions(associateInfo.GitHubUsername, githubScope(cfg, associateInfo), startDate, endDate)
 		if err != nil {
 			log.Printf("  Warning: Error fetching GitHub data for %s: %v", assocName, err)
 			continue
 		}
+		for _, capped := range githubData.CappedSearches {
+			log.Printf("  Warning: GitHub search %s", capped)
+		}
 
 		// Generate report
 		fmt.Println("  Generating HTML report...")
-		reportHTML := report.Generate(assocName, *quarter, *year, startDate, endDate, cfg.Jira.URL, jiraIssues, githubData)
+		reportHTML := report.Generate(assocName, *quarter, *year, startDate, endDate, cfg.Jira.URL, jiraData, githubData)
 
 		// Save report
 		outputFile := fmt.Sprintf("%s/%s_%s_%d.html", *outputDir, assocName, *quarter, *year)
@@ -107,6 +141,89 @@ func main() {
 	fmt.Printf("\n✓ All reports generated successfully in %s/\n", *outputDir)
 }
 
+func jiraOptions(cfg *config.Config) clients.JiraOptions {
+	return clients.JiraOptions{
+		AuthMode:            cfg.Jira.AuthMode,
+		Email:               cfg.Jira.Email,
+		StoryPointsField:    cfg.Jira.StoryPointsField,
+		DoneStatuses:        cfg.Jira.DoneStatuses,
+		ExcludedResolutions: cfg.Jira.ExcludedResolutions,
+		ExtraJQL:            cfg.Jira.ExtraJQL,
+		FlowMetrics:         cfg.Jira.FlowMetrics,
+		InProgressStatuses:  cfg.Jira.InProgressStatuses,
+		SprintField:         cfg.Jira.SprintField,
+		EpicLinkField:       cfg.Jira.EpicLinkField,
+		RemoteLinks:         cfg.Jira.RemoteLinks,
+	}
+}
+
+func githubOptions(cfg *config.Config) clients.GitHubOptions {
+	return clients.GitHubOptions{
+		Backend:        cfg.GitHub.Backend,
+		BaseURL:        cfg.GitHub.BaseURL,
+		UploadURL:      cfg.GitHub.UploadURL,
+		Concurrency:    cfg.GitHub.Concurrency,
+		FileBreakdown:  cfg.GitHub.FileBreakdown,
+		GeneratedPaths: cfg.GitHub.GeneratedPaths,
+		Commits:        cfg.GitHub.Commits,
+		Automation: clients.AutomationRules{
+			Authors: cfg.GitHub.Automation.Authors,
+			Titles:  cfg.GitHub.Automation.Titles,
+			Labels:  cfg.GitHub.Automation.Labels,
+		},
+	}
+}
+
+// githubScope combines the global and the associate's repository scope.
+func githubScope(cfg *config.Config, associate config.AssociateInfo) clients.RepoScope {
+	return clients.RepoScope{
+		Include: append(append([]string{}, cfg.GitHub.Scope.Include...), associate.GitHubScope.Include...),
+		Exclude: append(append([]string{}, cfg.GitHub.Scope.Exclude...), associate.GitHubScope.Exclude...),
+	}
+}
+
+func discoverJiraFields(cfg *config.Config) error {
+	jiraClient, err := clients.NewJiraClient(cfg.Jira.URL, cfg.Jira.Token, jiraOptions(cfg))
+	if err != nil {
+		return err
+	}
+	candidates, err := jiraClient.DiscoverFields()
+	if err != nil {
+		return err
+	}
+
+	var suggestions []string
+	for _, group := range []struct {
+		label     string
+		configKey string
+		fields    []clients.JiraField
+	}{
+		{"Story points", "story_points_field", candidates.StoryPoints},
+		{"Sprint", "sprint_field", candidates.Sprint},
+		{"Epic link", "epic_link_field", candidates.EpicLink},
+	} {
+		if len(group.fields) == 0 {
+			fmt.Printf("No %s field candidates were found on this Jira instance.\n\n", strings.ToLower(group.label))
+			continue
+		}
+
+		fmt.Printf("%s field candidates:\n", group.label)
+		for _, field := range group.fields {
+			fmt.Printf("  %-20s %s (%s)\n", field.ID, field.Name, field.Schema.Type)
+		}
+		fmt.Println()
+		suggestions = append(suggestions, fmt.Sprintf("    %s: %q", group.configKey, group.fields[0].ID))
+	}
+
+	if len(suggestions) > 0 {
+		fmt.Println("Suggested config:")
+		fmt.Println("  jira:")
+		fmt.Println(strings.Join(suggestions, "\n"))
+	}
+
+	return nil
+}
+
 func getQuarterDates(quarter string, year int) (time.Time, time.Time, error) {
 	var startMonth, endMonth time.Month
 	var endDay int
@@ -3,8 +3,9 @@ module github.com/acardace/contribution-report
 go 1.25.2
 
 require (
-	github.com/google/go-github/v57 v57.0.0 // indirect
-	github.com/google/go-querystring v1.1.0 // indirect
-	golang.org/x/oauth2 v0.32.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/google/go-github/v57 v57.0.0
+	golang.org/x/oauth2 v0.32.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/google/go-querystring v1.1.0 // indirect
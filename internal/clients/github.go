@@ -5,34 +5,165 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
 )
 
+// GitHub backends used to fetch pull requests and code reviews.
+const (
+	// GitHubBackendREST uses the search API plus one REST call per pull
+	// request for its details.
+	GitHubBackendREST = "rest"
+	// GitHubBackendGraphQL uses the GraphQL contributionsCollection, which
+	// returns pull request details in bulk.
+	GitHubBackendGraphQL = "graphql"
+)
+
 type GitHubClient struct {
 	client *github.Client
+	opts   GitHubOptions
+	// graphqlURL is the GraphQL endpoint, relative to the client's BaseURL
+	// on github.com.
+	graphqlURL string
+	// limiter coordinates rate limit waits between concurrent REST calls.
+	limiter rateLimitGate
 }
 
+// GitHubOptions holds the settings of a GitHubClient.
+type GitHubOptions struct {
+	// Backend is GitHubBackendREST (default) or GitHubBackendGraphQL.
+	Backend string
+	// BaseURL and UploadURL point the client at a GitHub Enterprise Server
+	// instance, e.g. "https://github.example.com/api/v3/". Both default to
+	// github.com; UploadURL defaults to BaseURL.
+	BaseURL   string
+	UploadURL string
+	// Concurrency is the number of pull requests enriched with REST calls at
+	// the same time. Defaults to DefaultGitHubConcurrency.
+	Concurrency int
+	// FileBreakdown lists the files of every pull request, to break code
+	// changes down by language and directory.
+	FileBreakdown bool
+	// GeneratedPaths are patterns of generated and vendored files, added to
+	// DefaultGeneratedPaths.
+	GeneratedPaths []string
+	// Commits searches the associate's commits, to find those that did not
+	// go through a pull request.
+	Commits bool
+	// Automation adds to DefaultAutomationRules, which flag pull requests
+	// opened by bots or automation.
+	Automation AutomationRules
+}
+
+// DefaultGitHubConcurrency is used when GitHubOptions.Concurrency is unset.
+const DefaultGitHubConcurrency = 4
+
 type GitHubData struct {
 	PullRequests []PullRequest
 	Issues       []Issue
 	CodeReviews  []CodeReview
+	// Commits are the associate's commits, when GitHubOptions.Commits is set.
+	Commits []Commit
+	// CappedSearches describes searches that matched more results than the
+	// search API returns, even after splitting their date range.
+	CappedSearches []string
+	// Scope is the repository scope the data was collected for.
+	Scope RepoScope
 }
 
+// Pull request states recorded in PullRequest.State. GitHub itself only knows
+// "open" and "closed"; closed pull requests are split by whether they merged.
+const (
+	PRStateOpen           = "open"
+	PRStateMerged         = "merged"
+	PRStateClosedUnmerged = "closed unmerged"
+)
+
 type PullRequest struct {
 	Number       int
 	Title        string
 	URL          string
 	State        string
 	CreatedAt    time.Time
+	ClosedAt     *time.Time
 	MergedAt     *time.Time
 	Repo         string
 	Commits      int
 	Additions    int
 	Deletions    int
 	ChangedFiles int
+	// FirstReviewAt is when someone other than the author first submitted a
+	// review, if anyone did.
+	FirstReviewAt *time.Time
+	// ReviewRounds counts the groups of reviews from others that are
+	// separated by new commits or force pushes.
+	ReviewRounds int
+	ForcePushes  int
+	Labels       []string
+	Milestone    string
+	Draft        bool
+	Body         string
+	HeadBranch   string
+	// JiraKeys are the Jira issue keys mentioned in the title, body or head
+	// branch, such as "PROJ-123".
+	JiraKeys []string
+	// ClosesIssues are the issues the pull request closes through closing
+	// keywords such as "Fixes #12" in its body.
+	ClosesIssues []IssueRef
+	// Automated is set for pull requests matching the automation rules,
+	// such as dependency bumps.
+	Automated bool
+	// Files lists the changed files, when GitHubOptions.FileBreakdown is set.
+	Files []ChangedFile
+	// EnrichError is set when the details or timeline of the pull request
+	// could not be fetched, leaving the fields that come from them empty.
+	EnrichError error
+
+	// timeline holds the events already read through GraphQL; nil when
+	// they still have to be listed over REST.
+	timeline []timelineEvent
+}
+
+// TimeToFirstReview returns the time from creation to the first review, and
+// false if the pull request was never reviewed.
+func (pr PullRequest) TimeToFirstReview() (time.Duration, bool) {
+	if pr.FirstReviewAt == nil {
+		return 0, false
+	}
+	return pr.FirstReviewAt.Sub(pr.CreatedAt), true
+}
+
+// TimeToMerge returns the time from creation to merge, and false if the pull
+// request was not merged.
+func (pr PullRequest) TimeToMerge() (time.Duration, bool) {
+	if pr.MergedAt == nil {
+		return 0, false
+	}
+	return pr.MergedAt.Sub(pr.CreatedAt), true
+}
+
+// Commit is a commit authored by the associate.
+type Commit struct {
+	SHA string
+	// Message is the first line of the commit message.
+	Message     string
+	URL         string
+	Repo        string
+	CommittedAt time.Time
+	// PullRequestURL is the pull request that contains the commit, or ""
+	// for a direct commit.
+	PullRequestURL string
+	// EnrichError is set when the pull requests of the commit could not be
+	// looked up, so it is not known whether it is a direct commit.
+	EnrichError error
+}
+
+// Direct reports whether the commit is known not to belong to a pull request.
+func (c Commit) Direct() bool {
+	return c.PullRequestURL == "" && c.EnrichError == nil
 }
 
 type Issue struct {
@@ -43,31 +174,142 @@ type Issue struct {
 	CreatedAt time.Time
 	ClosedAt  *time.Time
 	Repo      string
+	// Involvement lists the ways the associate was involved in the issue,
+	// in the order of the constants below.
+	Involvement []string
+	// Comments is the number of comments the associate wrote in the period.
+	Comments int
+}
+
+// Issue involvement kinds recorded in Issue.Involvement.
+const (
+	IssueAuthored  = "authored"
+	IssueAssigned  = "assigned"
+	IssueCommented = "commented"
+	IssueMentioned = "mentioned"
+)
+
+// Involved reports whether the associate was involved in the issue in the
+// given way.
+func (i Issue) Involved(kind string) bool {
+	for _, k := range i.Involvement {
+		if k == kind {
+			return true
+		}
+	}
+	return false
 }
 
 type CodeReview struct {
 	PRNumber  int
 	PRTitle   string
+	PRAuthor  string
 	URL       string
 	State     string
 	CreatedAt time.Time
 	Repo      string
+	Labels    []string
+	// Automated is set when the reviewed pull request matches the
+	// automation rules, such as a bot's dependency bump.
+	Automated bool
+	// Reviews are the reviews the associate submitted in the period, oldest
+	// first.
+	Reviews []ReviewSubmission
+	// InlineComments is the number of diff comments the associate left in
+	// the period.
+	InlineComments int
+	// Size of the reviewed pull request
+	Additions    int
+	Deletions    int
+	ChangedFiles int
+	// ReviewRequestedAt is when the associate was asked to review, for the
+	// request answered by the first review in Reviews. It is nil when the
+	// associate reviewed without being requested, or was only requested
+	// through a team.
+	ReviewRequestedAt *time.Time
+	// EnrichError is set when the reviews or timeline of the pull request
+	// could not be fetched, leaving the fields that come from them empty.
+	EnrichError error
+
+	// timeline holds the events already read through GraphQL; nil when
+	// they still have to be listed over REST.
+	timeline []timelineEvent
 }
 
-func NewGitHubClient(token string) *GitHubClient {
+// Turnaround returns how long the associate took to answer the review
+// request, and false when that is not known.
+func (r CodeReview) Turnaround() (time.Duration, bool) {
+	if r.ReviewRequestedAt == nil || len(r.Reviews) == 0 {
+		return 0, false
+	}
+	return r.Reviews[0].SubmittedAt.Sub(*r.ReviewRequestedAt), true
+}
+
+// Review verdicts recorded in ReviewSubmission.State.
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+	ReviewDismissed        = "DISMISSED"
+)
+
+// ReviewSubmission is a single review submitted on a pull request.
+type ReviewSubmission struct {
+	State       string
+	SubmittedAt time.Time
+}
+
+func NewGitHubClient(token string, opts GitHubOptions) (*GitHubClient, error) {
+	switch opts.Backend {
+	case "":
+		opts.Backend = GitHubBackendREST
+	case GitHubBackendREST, GitHubBackendGraphQL:
+	default:
+		return nil, fmt.Errorf("unknown github backend %q (must be %q or %q)", opts.Backend, GitHubBackendREST, GitHubBackendGraphQL)
+	}
+	if opts.Concurrency < 0 {
+		return nil, fmt.Errorf("github concurrency must not be negative, got %d", opts.Concurrency)
+	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = DefaultGitHubConcurrency
+	}
+	opts.GeneratedPaths = append(append([]string{}, DefaultGeneratedPaths...), opts.GeneratedPaths...)
+	opts.Automation = DefaultAutomationRules.merge(opts.Automation)
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(context.Background(), ts)
 
-	return &GitHubClient{
-		client: github.NewClient(tc),
+	client := github.NewClient(tc)
+	graphqlURL := "graphql"
+	if opts.BaseURL != "" {
+		uploadURL := opts.UploadURL
+		if uploadURL == "" {
+			uploadURL = opts.BaseURL
+		}
+		var err error
+		client, err = client.WithEnterpriseURLs(opts.BaseURL, uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("configuring GitHub Enterprise URLs: %w", err)
+		}
+
+		// GitHub Enterprise Server serves GraphQL at /api/graphql next to
+		// the REST API at /api/v3/.
+		graphqlURL = strings.TrimSuffix(client.BaseURL.String(), "v3/") + "graphql"
 	}
+
+	return &GitHubClient{
+		client:     client,
+		opts:       opts,
+		graphqlURL: graphqlURL,
+	}, nil
 }
 
-// handleRateLimit checks the error and response for rate limiting, waits if needed, and returns whether to retry
+// handleRateLimit checks the error and response for rate limiting, waits if needed, and returns whether to retry.
+// Only errors isRateLimited accepts are waited out; any other 403 is returned to the caller right away.
 func handleRateLimit(err error, resp *github.Response) bool {
-	if err == nil {
+	if err == nil || !isRateLimited(err, resp) {
 		return false
 	}
 
@@ -92,8 +334,8 @@ func handleRateLimit(err error, resp *github.Response) bool {
 		return true
 	}
 
-	// Check HTTP response for rate limit status codes
-	if resp != nil && (resp.StatusCode == 403 || resp.StatusCode == 429) {
+	// A 403 or 429 that GitHub marked as rate limited through its headers
+	if resp != nil {
 		// Try to parse Retry-After header
 		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
 			if seconds, err := strconv.Atoi(retryAfter); err == nil {
@@ -125,25 +367,45 @@ func handleRateLimit(err error, resp *github.Response) bool {
 	return false
 }
 
-func (g *GitHubClient) FetchContributions(username string, startDate, endDate time.Time) (*GitHubData, error) {
+// FetchContributions collects the pull requests, issues, code reviews and,
+// optionally, commits of a user, limited to the repositories in scope.
+func (g *GitHubClient) FetchContributions(username string, scope RepoScope, startDate, endDate time.Time) (*GitHubData, error) {
+	if err := scope.validate(); err != nil {
+		return nil, err
+	}
+
 	ctx := context.Background()
+	run := &fetchRun{username: username, startDate: startDate, endDate: endDate, scope: scope}
 	data := &GitHubData{
 		PullRequests: []PullRequest{},
 		Issues:       []Issue{},
 		CodeReviews:  []CodeReview{},
 	}
 
+	fetchPullRequests, fetchCodeReviews := g.fetchPullRequests, g.fetchCodeReviews
+	if g.opts.Backend == GitHubBackendGraphQL {
+		fetchPullRequests, fetchCodeReviews = g.fetchPullRequestsGraphQL, g.fetchCodeReviewsGraphQL
+	}
+
 	// Fetch Pull Requests
 	fmt.Println("  - Fetching pull requests...")
-	prs, err := g.fetchPullRequests(ctx, username, startDate, endDate)
+	prs, err := fetchPullRequests(ctx, run)
 	if err != nil {
 		return nil, fmt.Errorf("fetching pull requests: %w", err)
 	}
 	data.PullRequests = prs
 
+	fmt.Println("  - Fetching pull request timelines...")
+	g.fetchPullRequestLifecycles(ctx, run, data.PullRequests)
+
+	if g.opts.FileBreakdown {
+		fmt.Println("  - Fetching changed files...")
+		g.fetchChangedFiles(ctx, data.PullRequests)
+	}
+
 	// Fetch Issues
 	fmt.Println("  - Fetching issues...")
-	issues, err := g.fetchIssues(ctx, username, startDate, endDate)
+	issues, err := g.fetchIssues(ctx, run)
 	if err != nil {
 		return nil, fmt.Errorf("fetching issues: %w", err)
 	}
@@ -151,275 +413,145 @@ func (g *GitHubClient) FetchContributions(username string, startDate, endDate ti
 
 	// Fetch Code Reviews
 	fmt.Println("  - Fetching code reviews...")
-	reviews, err := g.fetchCodeReviews(ctx, username, startDate, endDate)
+	reviews, err := fetchCodeReviews(ctx, run)
 	if err != nil {
 		return nil, fmt.Errorf("fetching code reviews: %w", err)
 	}
 	data.CodeReviews = reviews
 
-	return data, nil
-}
-
-func (g *GitHubClient) fetchPullRequests(ctx context.Context, username string, startDate, endDate time.Time) ([]PullRequest, error) {
-	query := fmt.Sprintf("author:%s type:pr created:%s..%s",
-		username,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
-	)
-
-	opts := &github.SearchOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
-	}
-
-	var allPRs []PullRequest
-	for {
-		var result *github.IssuesSearchResult
-		var resp *github.Response
-		var err error
-
-		// Retry loop for rate limiting
-		for attempt := 0; attempt < 10; attempt++ {
-			result, resp, err = g.client.Search.Issues(ctx, query, opts)
-			if !handleRateLimit(err, resp) {
-				break
-			}
-			// Rate limit was handled, retry
-		}
-
+	if g.opts.Commits {
+		fmt.Println("  - Fetching commits...")
+		commits, err := g.fetchCommits(ctx, run)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("fetching commits: %w", err)
 		}
+		data.Commits = commits
+	}
 
-		for _, issue := range result.Issues {
-			repoName := extractRepo(*issue.HTMLURL)
-			owner, repo := splitRepoName(repoName)
-
-			pr := PullRequest{
-				Number:    *issue.Number,
-				Title:     *issue.Title,
-				URL:       *issue.HTMLURL,
-				State:     *issue.State,
-				CreatedAt: issue.CreatedAt.Time,
-				Repo:      repoName,
-			}
-
-			if issue.PullRequestLinks != nil && issue.ClosedAt != nil {
-				mergedAt := issue.ClosedAt.Time
-				pr.MergedAt = &mergedAt
-			}
-
-			// Fetch detailed PR info
-			if owner != "" && repo != "" {
-				prDetail, _, err := g.client.PullRequests.Get(ctx, owner, repo, *issue.Number)
-				if err == nil && prDetail != nil {
-					pr.Commits = safeInt(prDetail.Commits)
-					pr.Additions = safeInt(prDetail.Additions)
-					pr.Deletions = safeInt(prDetail.Deletions)
-					pr.ChangedFiles = safeInt(prDetail.ChangedFiles)
-				}
-			}
+	fmt.Println("  - Fetching review requests...")
+	g.fetchReviewRequests(ctx, run, data.CodeReviews)
 
-			allPRs = append(allPRs, pr)
-		}
+	g.classifyAutomation(run, data)
+	data.CappedSearches = run.capped
+	data.Scope = scope
 
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
-	}
-
-	return allPRs, nil
+	return data, nil
 }
 
-func (g *GitHubClient) fetchIssues(ctx context.Context, username string, startDate, endDate time.Time) ([]Issue, error) {
-	// Fetch created issues
-	query := fmt.Sprintf("author:%s type:issue created:%s..%s",
-		username,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
-	)
-
-	opts := &github.SearchOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
+func (g *GitHubClient) fetchPullRequests(ctx context.Context, run *fetchRun) ([]PullRequest, error) {
+	results, err := g.searchIssuesByRange(ctx, run, func(dateRange string) string {
+		return fmt.Sprintf("author:%s type:pr created:%s", run.username, dateRange)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	var allIssues []Issue
-	for {
-		var result *github.IssuesSearchResult
-		var resp *github.Response
-		var err error
-
-		// Retry loop for rate limiting
-		for attempt := 0; attempt < 10; attempt++ {
-			result, resp, err = g.client.Search.Issues(ctx, query, opts)
-			if !handleRateLimit(err, resp) {
-				break
-			}
+	var allPRs []PullRequest
+	for _, issue := range results {
+		pr := PullRequest{
+			Number:    *issue.Number,
+			Title:     *issue.Title,
+			URL:       *issue.HTMLURL,
+			State:     *issue.State,
+			CreatedAt: issue.CreatedAt.Time,
+			Repo:      issueRepo(issue),
+			Labels:    issueLabels(issue),
+			Milestone: issue.GetMilestone().GetTitle(),
+			Draft:     issue.GetDraft(),
+			Body:      issue.GetBody(),
 		}
+		pr.ClosesIssues = closedIssues(pr, pr.Body)
+		pr.JiraKeys = jiraKeys(pr.Title, pr.Body)
 
-		if err != nil {
-			return nil, err
+		if issue.ClosedAt != nil {
+			closedAt := issue.ClosedAt.Time
+			pr.ClosedAt = &closedAt
 		}
 
-		for _, issue := range result.Issues {
-			if issue.PullRequestLinks != nil {
-				continue // Skip PRs
-			}
-
-			var closedAt *time.Time
-			if issue.ClosedAt != nil {
-				closed := issue.ClosedAt.Time
-				closedAt = &closed
-			}
-
-			allIssues = append(allIssues, Issue{
-				Number:    *issue.Number,
-				Title:     *issue.Title,
-				URL:       *issue.HTMLURL,
-				State:     *issue.State,
-				CreatedAt: issue.CreatedAt.Time,
-				ClosedAt:  closedAt,
-				Repo:      extractRepo(*issue.HTMLURL),
-			})
-		}
+		allPRs = append(allPRs, pr)
+	}
 
-		if resp.NextPage == 0 {
-			break
+	forEachConcurrently(g.opts.Concurrency, len(allPRs), func(i int) {
+		if err := g.fetchPullRequestDetail(ctx, &allPRs[i]); err != nil {
+			log.Printf("    Warning: Error fetching details of %s: %v", allPRs[i].URL, err)
+			allPRs[i].EnrichError = err
 		}
-		opts.Page = resp.NextPage
-	}
+	})
 
-	// Also fetch issues where the user participated (commented)
-	participatedQuery := fmt.Sprintf("involves:%s type:issue updated:%s..%s -author:%s",
-		username,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
-		username,
-	)
+	return allPRs, nil
+}
 
-	opts = &github.SearchOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
+// fetchPullRequestDetail fills in the commits, size and merge state of a
+// pull request found through the search API, which cannot tell merged and
+// closed pull requests apart.
+func (g *GitHubClient) fetchPullRequestDetail(ctx context.Context, pr *PullRequest) error {
+	owner, repo := splitRepoName(pr.Repo)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("unknown repository %q", pr.Repo)
 	}
 
-	for {
-		var result *github.IssuesSearchResult
+	var prDetail *github.PullRequest
+	err := g.limiter.call(func() (*github.Response, error) {
 		var resp *github.Response
 		var err error
+		prDetail, resp, err = g.client.PullRequests.Get(ctx, owner, repo, pr.Number)
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
 
-		// Retry loop for rate limiting
-		for attempt := 0; attempt < 10; attempt++ {
-			result, resp, err = g.client.Search.Issues(ctx, participatedQuery, opts)
-			if !handleRateLimit(err, resp) {
-				break
-			}
-		}
-
-		if err != nil {
-			return nil, err
-		}
-
-		for _, issue := range result.Issues {
-			if issue.PullRequestLinks != nil {
-				continue // Skip PRs
-			}
-
-			var closedAt *time.Time
-			if issue.ClosedAt != nil {
-				closed := issue.ClosedAt.Time
-				closedAt = &closed
-			}
-
-			allIssues = append(allIssues, Issue{
-				Number:    *issue.Number,
-				Title:     *issue.Title,
-				URL:       *issue.HTMLURL,
-				State:     *issue.State,
-				CreatedAt: issue.CreatedAt.Time,
-				ClosedAt:  closedAt,
-				Repo:      extractRepo(*issue.HTMLURL),
-			})
-		}
-
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+	pr.Commits = safeInt(prDetail.Commits)
+	pr.Additions = safeInt(prDetail.Additions)
+	pr.Deletions = safeInt(prDetail.Deletions)
+	pr.ChangedFiles = safeInt(prDetail.ChangedFiles)
+	if prDetail.MergedAt != nil {
+		mergedAt := prDetail.MergedAt.Time
+		pr.MergedAt = &mergedAt
 	}
+	pr.State = prState(prDetail.GetState(), prDetail.GetMerged())
+	pr.HeadBranch = prDetail.GetHead().GetRef()
+	pr.JiraKeys = jiraKeys(pr.Title, pr.Body, pr.HeadBranch)
 
-	return allIssues, nil
+	return nil
 }
 
-func (g *GitHubClient) fetchCodeReviews(ctx context.Context, username string, startDate, endDate time.Time) ([]CodeReview, error) {
-	query := fmt.Sprintf("reviewed-by:%s type:pr reviewed:%s..%s -author:%s",
-		username,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
-		username,
-	)
-
-	opts := &github.SearchOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
+func (g *GitHubClient) fetchCodeReviews(ctx context.Context, run *fetchRun) ([]CodeReview, error) {
+	results, err := g.searchIssuesByRange(ctx, run, func(dateRange string) string {
+		return fmt.Sprintf("reviewed-by:%s type:pr reviewed:%s -author:%s", run.username, dateRange, run.username)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	var allReviews []CodeReview
-	for {
-		var result *github.IssuesSearchResult
-		var resp *github.Response
-		var err error
-
-		// Retry loop for rate limiting
-		for attempt := 0; attempt < 10; attempt++ {
-			result, resp, err = g.client.Search.Issues(ctx, query, opts)
-			if !handleRateLimit(err, resp) {
-				break
-			}
-		}
-
-		if err != nil {
-			return nil, err
-		}
-
-		for _, issue := range result.Issues {
-			allReviews = append(allReviews, CodeReview{
-				PRNumber:  *issue.Number,
-				PRTitle:   *issue.Title,
-				URL:       *issue.HTMLURL,
-				State:     *issue.State,
-				CreatedAt: issue.CreatedAt.Time,
-				Repo:      extractRepo(*issue.HTMLURL),
-			})
-		}
-
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+	for _, issue := range results {
+		allReviews = append(allReviews, CodeReview{
+			PRNumber:  *issue.Number,
+			PRTitle:   *issue.Title,
+			URL:       *issue.HTMLURL,
+			State:     *issue.State,
+			CreatedAt: issue.CreatedAt.Time,
+			Repo:      issueRepo(issue),
+			PRAuthor:  issue.GetUser().GetLogin(),
+			Labels:    issueLabels(issue),
+		})
 	}
 
+	g.enrichCodeReviews(ctx, run, allReviews)
+
 	return allReviews, nil
 }
 
-func extractRepo(url string) string {
-	// Extract repo name from URL like https://github.com/owner/repo/pull/123
-	// This is a simple extraction, you might want to make it more robust
-	var repo string
-	fmt.Sscanf(url, "https://github.com/%s", &repo)
-	// Remove the /pull/123 or /issues/123 part
-	for i := 0; i < len(repo); i++ {
-		if repo[i] == '/' {
-			count := 0
-			for j := 0; j <= i; j++ {
-				if repo[j] == '/' {
-					count++
-				}
-			}
-			if count == 2 {
-				repo = repo[:i]
-				break
-			}
-		}
+// issueRepo returns the "owner/repo" name of a search result. It is taken
+// from the API's repository_url ("<api base>/repos/owner/repo"), which works
+// for github.com and GitHub Enterprise Server alike.
+func issueRepo(issue *github.Issue) string {
+	repoURL := issue.GetRepositoryURL()
+	if i := strings.LastIndex(repoURL, "/repos/"); i >= 0 {
+		return repoURL[i+len("/repos/"):]
 	}
-	return repo
+	return ""
 }
 
 func splitRepoName(repoName string) (owner, repo string) {
@@ -439,6 +571,19 @@ func splitRepoName(repoName string) (owner, repo string) {
 	return
 }
 
+// prState maps GitHub's open/closed state and merged flag onto the PRState
+// constants.
+func prState(state string, merged bool) string {
+	switch {
+	case merged:
+		return PRStateMerged
+	case state == "closed":
+		return PRStateClosedUnmerged
+	default:
+		return PRStateOpen
+	}
+}
+
 func safeInt(val *int) int {
 	if val == nil {
 		return 0
@@ -0,0 +1,104 @@
+package clients
+
+import (
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// AutomationRules recognize pull requests opened by bots or automation, such
+// as dependency bumps and backports. Patterns are matched case-insensitively
+// against the whole value, and "*" matches any run of characters.
+type AutomationRules struct {
+	// Authors are patterns for the login of the pull request author.
+	Authors []string
+	// Titles are patterns for the pull request title.
+	Titles []string
+	// Labels are patterns for any of the pull request's labels.
+	Labels []string
+}
+
+// DefaultAutomationRules are always applied; configured rules add to them.
+// The labels are exact so that process labels such as cherry-pick-approved
+// do not mark human pull requests as automation.
+var DefaultAutomationRules = AutomationRules{
+	Authors: []string{"*[bot]", "*-bot", "*-robot", "dependabot*", "renovate*"},
+	Titles:  []string{"Bump *", "chore(deps)*", "build(deps)*", "fix(deps)*"},
+	Labels:  []string{"dependencies", "backport", "cherry-pick", "automated"},
+}
+
+func (r AutomationRules) merge(other AutomationRules) AutomationRules {
+	return AutomationRules{
+		Authors: append(append([]string{}, r.Authors...), other.Authors...),
+		Titles:  append(append([]string{}, r.Titles...), other.Titles...),
+		Labels:  append(append([]string{}, r.Labels...), other.Labels...),
+	}
+}
+
+// Automated reports whether a pull request with the given author, title and
+// labels matches the rules.
+func (r AutomationRules) Automated(author, title string, labels []string) bool {
+	if matchesAnyWildcard(author, r.Authors) || matchesAnyWildcard(title, r.Titles) {
+		return true
+	}
+	for _, label := range labels {
+		if matchesAnyWildcard(label, r.Labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyAutomation flags the pull requests and reviewed pull requests that
+// match the client's automation rules.
+func (g *GitHubClient) classifyAutomation(run *fetchRun, data *GitHubData) {
+	for i, pr := range data.PullRequests {
+		data.PullRequests[i].Automated = g.opts.Automation.Automated(run.username, pr.Title, pr.Labels)
+	}
+	for i, review := range data.CodeReviews {
+		data.CodeReviews[i].Automated = g.opts.Automation.Automated(review.PRAuthor, review.PRTitle, review.Labels)
+	}
+}
+
+func matchesAnyWildcard(s string, patterns []string) bool {
+	if s == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matchWildcard(strings.ToLower(pattern), strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard matches s against pattern, where "*" matches any run of
+// characters and every other character only matches itself.
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return s == pattern
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+func issueLabels(issue *github.Issue) []string {
+	var labels []string
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	return labels
+}
@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// fetchCommits finds the commits the user authored in the window with the
+// commit search API, and links each one to the pull request that contains
+// it, if any. Merge commits are left out.
+func (g *GitHubClient) fetchCommits(ctx context.Context, run *fetchRun) ([]Commit, error) {
+	results, err := g.searchCommitsByRange(ctx, run, func(dateRange string) string {
+		return fmt.Sprintf("author:%s committer-date:%s merge:false", run.username, dateRange)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, result := range results {
+		commit := Commit{
+			SHA:     result.GetSHA(),
+			Message: firstLine(result.GetCommit().GetMessage()),
+			URL:     result.GetHTMLURL(),
+			Repo:    result.GetRepository().GetFullName(),
+		}
+		if date := result.GetCommit().GetCommitter().Date; date != nil {
+			commit.CommittedAt = date.Time
+		}
+		commits = append(commits, commit)
+	}
+
+	forEachConcurrently(g.opts.Concurrency, len(commits), func(i int) {
+		prURL, err := g.commitPullRequest(ctx, commits[i])
+		if err != nil {
+			log.Printf("    Warning: Error looking up pull requests of %s: %v", commits[i].URL, err)
+			commits[i].EnrichError = err
+			return
+		}
+		commits[i].PullRequestURL = prURL
+	})
+
+	return commits, nil
+}
+
+// commitPullRequest returns the URL of a pull request containing the
+// commit, or "" if there is none.
+func (g *GitHubClient) commitPullRequest(ctx context.Context, commit Commit) (string, error) {
+	owner, repo := splitRepoName(commit.Repo)
+	if owner == "" || repo == "" {
+		return "", fmt.Errorf("unknown repository %q", commit.Repo)
+	}
+
+	var prs []*github.PullRequest
+	err := g.limiter.call(func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		prs, resp, err = g.client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, commit.SHA, nil)
+		return resp, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(prs) == 0 {
+		return "", nil
+	}
+	return prs[0].GetHTMLURL(), nil
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,180 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// ChangedFile is one file changed by a pull request.
+type ChangedFile struct {
+	Path     string
+	Language string
+	// Area is the top-level directory of the file, or "" for files at the
+	// repository root.
+	Area      string
+	Additions int
+	Deletions int
+	// Generated marks generated, vendored and lock files, whose changes do
+	// not reflect code the associate wrote.
+	Generated bool
+}
+
+// DefaultGeneratedPaths are the patterns that mark a changed file as
+// generated or vendored. A pattern ending in "/" matches a directory at any
+// depth; any other pattern is matched against the file name and the full
+// path with path.Match.
+var DefaultGeneratedPaths = []string{
+	"vendor/",
+	"node_modules/",
+	"third_party/",
+	"zz_generated*",
+	"*.pb.go",
+	"*_generated.go",
+	"*.gen.go",
+	"*.min.js",
+	"*.min.css",
+	"go.sum",
+	"package-lock.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"poetry.lock",
+	"composer.lock",
+}
+
+// languagesByExtension maps file extensions to the language reported for
+// them. Files with other extensions are reported as "Other".
+var languagesByExtension = map[string]string{
+	".go":    "Go",
+	".py":    "Python",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".rb":    "Ruby",
+	".rs":    "Rust",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".swift": "Swift",
+	".php":   "PHP",
+	".scala": "Scala",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".html":  "HTML",
+	".css":   "CSS",
+	".scss":  "CSS",
+	".sql":   "SQL",
+	".proto": "Protocol Buffers",
+	".yaml":  "YAML",
+	".yml":   "YAML",
+	".json":  "JSON",
+	".toml":  "TOML",
+	".xml":   "XML",
+	".md":    "Markdown",
+	".rst":   "reStructuredText",
+	".tf":    "Terraform",
+}
+
+// languagesByName covers files recognized by their name rather than their
+// extension.
+var languagesByName = map[string]string{
+	"Makefile":   "Makefile",
+	"Dockerfile": "Dockerfile",
+	"go.mod":     "Go",
+	"go.sum":     "Go",
+}
+
+// fetchChangedFiles lists the files of every pull request. Pull requests
+// whose files cannot be listed are left without them, with EnrichError set.
+func (g *GitHubClient) fetchChangedFiles(ctx context.Context, prs []PullRequest) {
+	forEachConcurrently(g.opts.Concurrency, len(prs), func(i int) {
+		files, err := g.listChangedFiles(ctx, prs[i])
+		if err != nil {
+			log.Printf("    Warning: Error listing files of %s: %v", prs[i].URL, err)
+			if prs[i].EnrichError == nil {
+				prs[i].EnrichError = err
+			}
+			return
+		}
+		prs[i].Files = files
+	})
+}
+
+func (g *GitHubClient) listChangedFiles(ctx context.Context, pr PullRequest) ([]ChangedFile, error) {
+	owner, repo := splitRepoName(pr.Repo)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("unknown repository %q", pr.Repo)
+	}
+
+	commitFiles, err := listAll(&g.limiter, func(opts github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+		return g.client.PullRequests.ListFiles(ctx, owner, repo, pr.Number, &opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]ChangedFile, 0, len(commitFiles))
+	for _, f := range commitFiles {
+		files = append(files, g.classifyFile(f.GetFilename(), f.GetAdditions(), f.GetDeletions()))
+	}
+	return files, nil
+}
+
+func (g *GitHubClient) classifyFile(filePath string, additions, deletions int) ChangedFile {
+	file := ChangedFile{
+		Path:      filePath,
+		Language:  fileLanguage(filePath),
+		Additions: additions,
+		Deletions: deletions,
+		Generated: matchesPathPattern(filePath, g.opts.GeneratedPaths),
+	}
+	if i := strings.Index(filePath, "/"); i > 0 {
+		file.Area = filePath[:i]
+	}
+	return file
+}
+
+func fileLanguage(filePath string) string {
+	name := path.Base(filePath)
+	if language, ok := languagesByName[name]; ok {
+		return language
+	}
+	if language, ok := languagesByExtension[strings.ToLower(path.Ext(name))]; ok {
+		return language
+	}
+	return "Other"
+}
+
+// matchesPathPattern reports whether filePath matches one of patterns, as
+// described for DefaultGeneratedPaths.
+func matchesPathPattern(filePath string, patterns []string) bool {
+	name := path.Base(filePath)
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+			if strings.HasPrefix(filePath, dir+"/") || strings.Contains(filePath, "/"+dir+"/") {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, filePath); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,439 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// graphqlPullRequestFields selects what the REST backend gets from
+// PullRequests.Get, so both backends fill in PullRequest the same way. It
+// also selects the timeline events the review history is read from, which
+// the REST backend lists with one more call per pull request.
+const graphqlPullRequestFields = `
+	number
+	title
+	url
+	state
+	merged
+	createdAt
+	closedAt
+	mergedAt
+	additions
+	deletions
+	changedFiles
+	commits { totalCount }
+	repository { nameWithOwner }
+	author { login }
+	labels(first: 50) { nodes { name } }
+	milestone { title }
+	isDraft
+	body
+	headRefName
+	timelineItems(first: 100, itemTypes: [PULL_REQUEST_REVIEW, PULL_REQUEST_COMMIT, HEAD_REF_FORCE_PUSHED_EVENT, REVIEW_REQUESTED_EVENT, REVIEW_REQUEST_REMOVED_EVENT]) {
+		pageInfo { hasNextPage endCursor }
+		nodes {
+			__typename
+			... on PullRequestReview { author { login } submittedAt }
+			... on ReviewRequestedEvent { createdAt requestedReviewer { ... on User { login } } }
+			... on ReviewRequestRemovedEvent { createdAt requestedReviewer { ... on User { login } } }
+		}
+	}`
+
+const graphqlPullRequestsQuery = `
+query($login: String!, $from: DateTime!, $to: DateTime!, $cursor: String) {
+  user(login: $login) {
+    contributionsCollection(from: $from, to: $to) {
+      pullRequestContributions(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          pullRequest {` + graphqlPullRequestFields + `
+          }
+        }
+      }
+    }
+  }
+}`
+
+const graphqlReviewsQuery = `
+query($login: String!, $from: DateTime!, $to: DateTime!, $cursor: String) {
+  user(login: $login) {
+    contributionsCollection(from: $from, to: $to) {
+      pullRequestReviewContributions(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          pullRequestReview {
+            state
+            submittedAt
+            comments { totalCount }
+          }
+          pullRequest {` + graphqlPullRequestFields + `
+          }
+        }
+      }
+    }
+  }
+}`
+
+type graphqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphqlPullRequest struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	URL          string     `json:"url"`
+	State        string     `json:"state"`
+	Merged       bool       `json:"merged"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	ClosedAt     *time.Time `json:"closedAt"`
+	MergedAt     *time.Time `json:"mergedAt"`
+	Additions    int        `json:"additions"`
+	Deletions    int        `json:"deletions"`
+	ChangedFiles int        `json:"changedFiles"`
+	Commits      struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"commits"`
+	Repository struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	IsDraft       bool            `json:"isDraft"`
+	Body          string          `json:"body"`
+	HeadRefName   string          `json:"headRefName"`
+	TimelineItems graphqlTimeline `json:"timelineItems"`
+}
+
+type graphqlTimeline struct {
+	PageInfo graphqlPageInfo `json:"pageInfo"`
+	Nodes    []struct {
+		Typename string `json:"__typename"`
+		Author   *struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		SubmittedAt       *time.Time `json:"submittedAt"`
+		CreatedAt         *time.Time `json:"createdAt"`
+		RequestedReviewer *struct {
+			Login string `json:"login"`
+		} `json:"requestedReviewer"`
+	} `json:"nodes"`
+}
+
+// events converts the timeline to the events the REST timeline lists. It
+// returns nil when the pull request has more events than one query returns,
+// so that its timeline is listed over REST instead.
+func (t graphqlTimeline) events() []timelineEvent {
+	if t.PageInfo.HasNextPage {
+		return nil
+	}
+
+	events := make([]timelineEvent, 0, len(t.Nodes))
+	for _, node := range t.Nodes {
+		var event timelineEvent
+		switch node.Typename {
+		case "PullRequestReview":
+			event = timelineEvent{Event: "reviewed", At: node.SubmittedAt}
+			if node.Author != nil {
+				event.Login = node.Author.Login
+			}
+		case "PullRequestCommit":
+			event = timelineEvent{Event: "committed"}
+		case "HeadRefForcePushedEvent":
+			event = timelineEvent{Event: "head_ref_force_pushed"}
+		case "ReviewRequestedEvent", "ReviewRequestRemovedEvent":
+			event = timelineEvent{Event: "review_requested", At: node.CreatedAt}
+			if node.Typename == "ReviewRequestRemovedEvent" {
+				event.Event = "review_request_removed"
+			}
+			if node.RequestedReviewer != nil {
+				event.Login = node.RequestedReviewer.Login
+			}
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func (pr graphqlPullRequest) labels() []string {
+	var labels []string
+	for _, label := range pr.Labels.Nodes {
+		labels = append(labels, label.Name)
+	}
+	return labels
+}
+
+type graphqlReview struct {
+	State       string     `json:"state"`
+	SubmittedAt *time.Time `json:"submittedAt"`
+	Comments    struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"comments"`
+}
+
+// graphqlContributions is the part of the response shared by both queries;
+// only the connection that was asked for is filled in.
+type graphqlContributions struct {
+	User *struct {
+		ContributionsCollection struct {
+			PullRequestContributions *struct {
+				PageInfo graphqlPageInfo `json:"pageInfo"`
+				Nodes    []struct {
+					PullRequest graphqlPullRequest `json:"pullRequest"`
+				} `json:"nodes"`
+			} `json:"pullRequestContributions"`
+			PullRequestReviewContributions *struct {
+				PageInfo graphqlPageInfo `json:"pageInfo"`
+				Nodes    []struct {
+					PullRequestReview graphqlReview      `json:"pullRequestReview"`
+					PullRequest       graphqlPullRequest `json:"pullRequest"`
+				} `json:"nodes"`
+			} `json:"pullRequestReviewContributions"`
+		} `json:"contributionsCollection"`
+	} `json:"user"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// fetchPullRequestsGraphQL is the GraphQL counterpart of fetchPullRequests.
+func (g *GitHubClient) fetchPullRequestsGraphQL(ctx context.Context, run *fetchRun) ([]PullRequest, error) {
+	var allPRs []PullRequest
+	cursor := ""
+	for {
+		var result graphqlContributions
+		if err := g.graphql(ctx, graphqlPullRequestsQuery, contributionsVariables(run, cursor), &result); err != nil {
+			return nil, err
+		}
+		if result.User == nil {
+			return nil, fmt.Errorf("GitHub user %q not found", run.username)
+		}
+
+		conn := result.User.ContributionsCollection.PullRequestContributions
+		for _, node := range conn.Nodes {
+			if !run.scope.Allows(node.PullRequest.Repository.NameWithOwner) {
+				continue
+			}
+			allPRs = append(allPRs, node.PullRequest.toPullRequest())
+		}
+
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		cursor = conn.PageInfo.EndCursor
+	}
+
+	return allPRs, nil
+}
+
+// fetchCodeReviewsGraphQL is the GraphQL counterpart of fetchCodeReviews.
+// contributionsCollection has one contribution per submitted review, so the
+// reviews of a pull request reviewed several times are collected on a single
+// CodeReview.
+func (g *GitHubClient) fetchCodeReviewsGraphQL(ctx context.Context, run *fetchRun) ([]CodeReview, error) {
+	var allReviews []CodeReview
+	seen := map[string]int{}
+	cursor := ""
+	for {
+		var result graphqlContributions
+		if err := g.graphql(ctx, graphqlReviewsQuery, contributionsVariables(run, cursor), &result); err != nil {
+			return nil, err
+		}
+		if result.User == nil {
+			return nil, fmt.Errorf("GitHub user %q not found", run.username)
+		}
+
+		conn := result.User.ContributionsCollection.PullRequestReviewContributions
+		for _, node := range conn.Nodes {
+			pr := node.PullRequest
+			if pr.Author != nil && strings.EqualFold(pr.Author.Login, run.username) {
+				continue
+			}
+			if !run.scope.Allows(pr.Repository.NameWithOwner) {
+				continue
+			}
+
+			i, ok := seen[pr.URL]
+			if !ok {
+				// Match the issue state reported by the search API
+				state := "open"
+				if pr.State != "OPEN" {
+					state = "closed"
+				}
+
+				author := ""
+				if pr.Author != nil {
+					author = pr.Author.Login
+				}
+
+				i = len(allReviews)
+				seen[pr.URL] = i
+				allReviews = append(allReviews, CodeReview{
+					PRNumber:     pr.Number,
+					PRTitle:      pr.Title,
+					PRAuthor:     author,
+					URL:          pr.URL,
+					State:        state,
+					CreatedAt:    pr.CreatedAt,
+					Repo:         pr.Repository.NameWithOwner,
+					Labels:       pr.labels(),
+					Additions:    pr.Additions,
+					Deletions:    pr.Deletions,
+					ChangedFiles: pr.ChangedFiles,
+					timeline:     pr.TimelineItems.events(),
+				})
+			}
+
+			review := node.PullRequestReview
+			if review.SubmittedAt != nil {
+				allReviews[i].Reviews = append(allReviews[i].Reviews, ReviewSubmission{
+					State:       review.State,
+					SubmittedAt: *review.SubmittedAt,
+				})
+			}
+			allReviews[i].InlineComments += review.Comments.TotalCount
+		}
+
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		cursor = conn.PageInfo.EndCursor
+	}
+
+	for i := range allReviews {
+		reviews := allReviews[i].Reviews
+		sort.Slice(reviews, func(a, b int) bool {
+			return reviews[a].SubmittedAt.Before(reviews[b].SubmittedAt)
+		})
+	}
+
+	return allReviews, nil
+}
+
+func (pr graphqlPullRequest) toPullRequest() PullRequest {
+	state := "open"
+	if pr.State != "OPEN" {
+		state = "closed"
+	}
+
+	converted := PullRequest{
+		Number:       pr.Number,
+		Title:        pr.Title,
+		URL:          pr.URL,
+		State:        prState(state, pr.Merged),
+		CreatedAt:    pr.CreatedAt,
+		ClosedAt:     pr.ClosedAt,
+		MergedAt:     pr.MergedAt,
+		Repo:         pr.Repository.NameWithOwner,
+		Commits:      pr.Commits.TotalCount,
+		Additions:    pr.Additions,
+		Deletions:    pr.Deletions,
+		ChangedFiles: pr.ChangedFiles,
+		Labels:       pr.labels(),
+		Draft:        pr.IsDraft,
+		Body:         pr.Body,
+		HeadBranch:   pr.HeadRefName,
+		timeline:     pr.TimelineItems.events(),
+	}
+	if pr.Milestone != nil {
+		converted.Milestone = pr.Milestone.Title
+	}
+	converted.ClosesIssues = closedIssues(converted, pr.Body)
+	converted.JiraKeys = jiraKeys(pr.Title, pr.Body, pr.HeadRefName)
+
+	return converted
+}
+
+func contributionsVariables(run *fetchRun, cursor string) map[string]interface{} {
+	vars := map[string]interface{}{
+		"login": run.username,
+		"from":  run.startDate.Format(time.RFC3339),
+		"to":    run.endDate.Format(time.RFC3339),
+	}
+	if cursor != "" {
+		vars["cursor"] = cursor
+	}
+	return vars
+}
+
+// graphql runs a GraphQL query and decodes its data into out, retrying on
+// rate limits through the same gate as the REST calls. GraphQL reports
+// exhausted rate limits as a RATE_LIMITED error in a 200 response, which the
+// gate cannot see, so those wait for the reset time from the response
+// headers.
+func (g *GitHubClient) graphql(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	body := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		var result graphqlResponse
+		var resp *github.Response
+		err := g.limiter.call(func() (*github.Response, error) {
+			req, err := g.client.NewRequest("POST", g.graphqlURL, body)
+			if err != nil {
+				return nil, err
+			}
+			result = graphqlResponse{}
+			resp, err = g.client.Do(ctx, req, &result)
+			return resp, err
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(result.Errors) > 0 {
+			if result.Errors[0].Type == "RATE_LIMITED" {
+				waitForGraphQLReset(resp)
+				continue
+			}
+			messages := make([]string, len(result.Errors))
+			for i, e := range result.Errors {
+				messages[i] = e.Message
+			}
+			return fmt.Errorf("GitHub GraphQL error: %s", strings.Join(messages, "; "))
+		}
+
+		return json.Unmarshal(result.Data, out)
+	}
+
+	return fmt.Errorf("GitHub GraphQL rate limit still exceeded after retries")
+}
+
+func waitForGraphQLReset(resp *github.Response) {
+	waitTime := time.Minute
+	if resp != nil {
+		if resetUnix, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if until := time.Until(time.Unix(resetUnix, 0)); until > 0 {
+				waitTime = until + time.Second
+			}
+		}
+	}
+	log.Printf("    GraphQL rate limit reached. Waiting %v...", waitTime.Round(time.Second))
+	time.Sleep(waitTime)
+}
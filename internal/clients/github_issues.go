@@ -0,0 +1,138 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// issueInvolvementQueries finds the issues of each involvement kind. Issues
+// the user authored count when they were created in the window; the others
+// when they were updated in it, whoever opened them, so that an older issue
+// of the user's own still counts for work done on it in the window.
+var issueInvolvementQueries = []struct {
+	kind  string
+	query string
+}{
+	{IssueAuthored, "author:%[1]s type:issue created:%[2]s"},
+	{IssueAssigned, "assignee:%[1]s type:issue updated:%[2]s"},
+	{IssueCommented, "commenter:%[1]s type:issue updated:%[2]s"},
+	{IssueMentioned, "mentions:%[1]s type:issue updated:%[2]s"},
+}
+
+// fetchIssues collects the issues the user authored, was assigned to,
+// commented on or was mentioned in, once each with every way they were
+// involved. An issue only counts as commented when the user commented in the
+// window; issues found only through older comments are dropped.
+func (g *GitHubClient) fetchIssues(ctx context.Context, run *fetchRun) ([]Issue, error) {
+	var allIssues []Issue
+	byURL := map[string]int{}
+	for _, q := range issueInvolvementQueries {
+		results, err := g.searchIssuesByRange(ctx, run, func(dateRange string) string {
+			return fmt.Sprintf(q.query, run.username, dateRange)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching %s issues: %w", q.kind, err)
+		}
+
+		for _, issue := range results {
+			if issue.PullRequestLinks != nil {
+				continue // Skip PRs
+			}
+
+			i, ok := byURL[issue.GetHTMLURL()]
+			if !ok {
+				i = len(allIssues)
+				byURL[issue.GetHTMLURL()] = i
+				allIssues = append(allIssues, newIssue(issue))
+			}
+			if !allIssues[i].Involved(q.kind) {
+				allIssues[i].Involvement = append(allIssues[i].Involvement, q.kind)
+			}
+		}
+	}
+
+	// Issues whose comments cannot be counted keep their commented kind.
+	uncounted := make([]bool, len(allIssues))
+	forEachConcurrently(g.opts.Concurrency, len(allIssues), func(i int) {
+		if !allIssues[i].Involved(IssueCommented) {
+			return
+		}
+		comments, err := g.countIssueComments(ctx, run, allIssues[i])
+		if err != nil {
+			log.Printf("    Warning: Error fetching comments of %s: %v", allIssues[i].URL, err)
+			uncounted[i] = true
+			return
+		}
+		allIssues[i].Comments = comments
+	})
+
+	kept := []Issue{}
+	for i, issue := range allIssues {
+		if issue.Involved(IssueCommented) && issue.Comments == 0 && !uncounted[i] {
+			issue.Involvement = removeString(issue.Involvement, IssueCommented)
+		}
+		if len(issue.Involvement) > 0 {
+			kept = append(kept, issue)
+		}
+	}
+
+	return kept, nil
+}
+
+func newIssue(issue *github.Issue) Issue {
+	converted := Issue{
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		URL:       issue.GetHTMLURL(),
+		State:     issue.GetState(),
+		CreatedAt: issue.GetCreatedAt().Time,
+		Repo:      issueRepo(issue),
+	}
+	if issue.ClosedAt != nil {
+		closedAt := issue.ClosedAt.Time
+		converted.ClosedAt = &closedAt
+	}
+	return converted
+}
+
+// countIssueComments counts the comments the user wrote on an issue in the
+// window.
+func (g *GitHubClient) countIssueComments(ctx context.Context, run *fetchRun, issue Issue) (int, error) {
+	owner, repo := splitRepoName(issue.Repo)
+	if owner == "" || repo == "" {
+		return 0, fmt.Errorf("unknown repository %q", issue.Repo)
+	}
+
+	since := run.startDate
+	comments, err := listAll(&g.limiter, func(opts github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+		return g.client.Issues.ListComments(ctx, owner, repo, issue.Number, &github.IssueListCommentsOptions{
+			Since:       &since,
+			ListOptions: opts,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, comment := range comments {
+		if strings.EqualFold(comment.GetUser().GetLogin(), run.username) && comment.CreatedAt != nil && run.inPeriod(comment.CreatedAt.Time) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func removeString(values []string, s string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != s {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,115 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// timelineEvent is a pull request timeline event, as far as the review
+// history needs it. Both backends produce the same events, named after the
+// REST timeline.
+type timelineEvent struct {
+	// Event is "reviewed", "committed", "head_ref_force_pushed",
+	// "review_requested" or "review_request_removed".
+	Event string
+	// Login is the reviewer of a review, or the user asked to review for
+	// review requests. It is empty for requests addressed to a team.
+	Login string
+	// At is when a review was submitted or a review request was made or
+	// removed.
+	At *time.Time
+}
+
+// listTimeline lists the timeline of a pull request over REST.
+func (g *GitHubClient) listTimeline(ctx context.Context, repoName string, number int) ([]timelineEvent, error) {
+	owner, repo := splitRepoName(repoName)
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("unknown repository %q", repoName)
+	}
+
+	items, err := listAll(&g.limiter, func(opts github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+		return g.client.Issues.ListIssueTimeline(ctx, owner, repo, number, &opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]timelineEvent, 0, len(items))
+	for _, item := range items {
+		event := timelineEvent{Event: item.GetEvent()}
+		switch event.Event {
+		case "reviewed":
+			event.Login = item.GetUser().GetLogin()
+			if item.SubmittedAt != nil {
+				event.At = &item.SubmittedAt.Time
+			}
+		case "review_requested", "review_request_removed":
+			event.Login = item.GetReviewer().GetLogin()
+			if item.CreatedAt != nil {
+				event.At = &item.CreatedAt.Time
+			}
+		case "committed", "head_ref_force_pushed":
+		default:
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// fetchPullRequestLifecycles fills in the review and push history of the
+// associate's pull requests from their timelines. Pull requests whose
+// timeline cannot be fetched are left without it, with EnrichError set.
+func (g *GitHubClient) fetchPullRequestLifecycles(ctx context.Context, run *fetchRun, prs []PullRequest) {
+	forEachConcurrently(g.opts.Concurrency, len(prs), func(i int) {
+		if err := g.fetchPullRequestLifecycle(ctx, run, &prs[i]); err != nil {
+			log.Printf("    Warning: Error fetching timeline of %s: %v", prs[i].URL, err)
+			if prs[i].EnrichError == nil {
+				prs[i].EnrichError = err
+			}
+		}
+	})
+}
+
+func (g *GitHubClient) fetchPullRequestLifecycle(ctx context.Context, run *fetchRun, pr *PullRequest) error {
+	events := pr.timeline
+	if events == nil {
+		var err error
+		if events, err = g.listTimeline(ctx, pr.Repo, pr.Number); err != nil {
+			return err
+		}
+	}
+
+	// A round starts with the first review from someone other than the
+	// author and ends when the author pushes again.
+	inRound := false
+	for _, event := range events {
+		switch event.Event {
+		case "reviewed":
+			if strings.EqualFold(event.Login, run.username) || event.At == nil {
+				continue
+			}
+			if pr.FirstReviewAt == nil {
+				submitted := *event.At
+				pr.FirstReviewAt = &submitted
+			}
+			if !inRound {
+				pr.ReviewRounds++
+				inRound = true
+			}
+		case "committed":
+			inRound = false
+		case "head_ref_force_pushed":
+			pr.ForcePushes++
+			inRound = false
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IssueRef is an issue referenced by a pull request.
+type IssueRef struct {
+	Repo   string
+	Number int
+	URL    string
+}
+
+// closingKeywordPattern matches GitHub's closing keywords followed by an
+// issue reference: "#12", "owner/repo#12" or an issue URL.
+var closingKeywordPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:https?://[^\s/]+/([\w.-]+/[\w.-]+)/issues/(\d+)|([\w.-]+/[\w.-]+)?#(\d+))\b`)
+
+// jiraKeyPattern matches Jira issue keys such as "PROJ-123".
+var jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[1-9][0-9]*\b`)
+
+// jiraKeys returns the Jira keys found in texts, in order of appearance and
+// without duplicates. Branch names are often lowercase, so texts are
+// matched in uppercase too. Not every match is a Jira key ("UTF-8"), so
+// callers should only use keys they can find in Jira.
+func jiraKeys(texts ...string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, key := range jiraKeyPattern.FindAllString(strings.ToUpper(text), -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// closedIssues returns the issues a pull request closes through closing
+// keywords in its body, in order of appearance and without duplicates.
+// Issue URLs are built from the pull request URL, so they point to the same
+// GitHub instance.
+func closedIssues(pr PullRequest, body string) []IssueRef {
+	webBase := strings.TrimSuffix(pr.URL, fmt.Sprintf("/%s/pull/%d", pr.Repo, pr.Number))
+
+	var refs []IssueRef
+	seen := map[string]bool{}
+	for _, match := range closingKeywordPattern.FindAllStringSubmatch(body, -1) {
+		repo, number := match[1], match[2]
+		if number == "" {
+			repo, number = match[3], match[4]
+		}
+		if repo == "" {
+			repo = pr.Repo
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			continue
+		}
+
+		ref := IssueRef{Repo: repo, Number: n, URL: fmt.Sprintf("%s/%s/issues/%d", webBase, repo, n)}
+		if seen[ref.URL] {
+			continue
+		}
+		seen[ref.URL] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
@@ -0,0 +1,119 @@
+package clients
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// rateLimitGate shares rate limit waits between workers. Calls run under a
+// read lock, and a worker that hits a rate limit waits it out under the
+// write lock, so no other call starts until the limit has reset. Workers
+// whose calls failed during someone else's wait retry without waiting again.
+type rateLimitGate struct {
+	mu sync.RWMutex
+	// waits counts the rate limit waits so far.
+	waits int
+	// wait waits out a rate limit and reports whether to retry. It defaults
+	// to handleRateLimit.
+	wait func(err error, resp *github.Response) bool
+}
+
+// call runs call, retrying it for as long as handleRateLimit waited out a
+// rate limit. Other errors, such as a 403 for a repository the token cannot
+// access, are returned right away. Every REST, search and GraphQL request
+// goes through it.
+func (gate *rateLimitGate) call(call func() (*github.Response, error)) error {
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		gate.mu.RLock()
+		waits := gate.waits
+		var resp *github.Response
+		resp, err = call()
+		gate.mu.RUnlock()
+		if err == nil {
+			return nil
+		}
+		if !isRateLimited(err, resp) {
+			return err
+		}
+
+		gate.mu.Lock()
+		if gate.waits != waits {
+			gate.mu.Unlock()
+			continue
+		}
+		wait := gate.wait
+		if wait == nil {
+			wait = handleRateLimit
+		}
+		retry := wait(err, resp)
+		if retry {
+			gate.waits++
+		}
+		gate.mu.Unlock()
+		if !retry {
+			break
+		}
+	}
+	return err
+}
+
+// isRateLimited reports whether a failed call hit a rate limit. go-github
+// reports primary and secondary limits as their own error types; a bare 403
+// or 429 only counts when GitHub says the limit is used up or when to retry,
+// as a 403 also means the token may not access the resource.
+func isRateLimited(err error, resp *github.Response) bool {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return true
+	}
+	if resp == nil || (resp.StatusCode != 403 && resp.StatusCode != 429) {
+		return false
+	}
+	return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+}
+
+// listAll pages through a REST list endpoint, waiting out rate limits.
+func listAll[T any](gate *rateLimitGate, list func(opts github.ListOptions) ([]T, *github.Response, error)) ([]T, error) {
+	var all []T
+	opts := github.ListOptions{PerPage: 100}
+	for {
+		var page []T
+		var resp *github.Response
+		err := gate.call(func() (*github.Response, error) {
+			var err error
+			page, resp, err = list(opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// forEachConcurrently calls fn for every index below n, running at most
+// concurrency calls at a time, and returns once all of them are done.
+func forEachConcurrently(concurrency, n int, fn func(i int)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package clients
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestRateLimitGateWaitsOncePerLimit(t *testing.T) {
+	const workers = 4
+
+	var limited atomic.Bool
+	limited.Store(true)
+	var waits atomic.Int32
+	gate := &rateLimitGate{
+		wait: func(err error, resp *github.Response) bool {
+			waits.Add(1)
+			limited.Store(false)
+			return true
+		},
+	}
+
+	// Every worker's first call runs until all of them have started, so
+	// they all hit the same rate limit before any of them waits it out.
+	var started sync.WaitGroup
+	started.Add(workers)
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			first := true
+			errs[i] = gate.call(func() (*github.Response, error) {
+				if first {
+					first = false
+					started.Done()
+					started.Wait()
+				}
+				if limited.Load() {
+					return nil, &github.RateLimitError{}
+				}
+				return &github.Response{}, nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("worker %d: %v", i, err)
+		}
+	}
+	if n := waits.Load(); n != 1 {
+		t.Errorf("waited %d times, want 1", n)
+	}
+}
+
+func TestRateLimitGateFailsFastOnForbidden(t *testing.T) {
+	gate := &rateLimitGate{
+		wait: func(err error, resp *github.Response) bool {
+			t.Error("waited on a 403 that is not a rate limit")
+			return true
+		},
+	}
+
+	calls := 0
+	err := gate.call(func() (*github.Response, error) {
+		calls++
+		resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+		return &github.Response{Response: resp}, &github.ErrorResponse{Response: resp, Message: "Resource not accessible by integration"}
+	})
+	if err == nil {
+		t.Fatal("got no error, want the 403")
+	}
+	if calls != 1 {
+		t.Errorf("called %d times, want 1", calls)
+	}
+}
+
+func TestHandleRateLimitIgnoresForbidden(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{"X-Ratelimit-Reset": []string{"4102444800"}}}
+	err := &github.ErrorResponse{Response: resp, Message: "Resource protected by organization SAML enforcement"}
+	if handleRateLimit(err, &github.Response{Response: resp}) {
+		t.Error("handleRateLimit waited on a 403 that is not a rate limit")
+	}
+}
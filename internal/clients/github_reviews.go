@@ -0,0 +1,141 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// enrichCodeReviews adds the associate's review verdicts, inline comment
+// counts and the pull request size to reviews found through the search API.
+// A review that cannot be enriched is kept with what the search returned,
+// with EnrichError set.
+func (g *GitHubClient) enrichCodeReviews(ctx context.Context, run *fetchRun, reviews []CodeReview) {
+	forEachConcurrently(g.opts.Concurrency, len(reviews), func(i int) {
+		if err := g.enrichCodeReview(ctx, run, &reviews[i]); err != nil {
+			log.Printf("    Warning: Error fetching review details for %s: %v", reviews[i].URL, err)
+			reviews[i].EnrichError = err
+		}
+	})
+}
+
+func (g *GitHubClient) enrichCodeReview(ctx context.Context, run *fetchRun, review *CodeReview) error {
+	owner, repo := splitRepoName(review.Repo)
+	if owner == "" || repo == "" {
+		return fmt.Errorf("unknown repository %q", review.Repo)
+	}
+
+	var prDetail *github.PullRequest
+	err := g.limiter.call(func() (*github.Response, error) {
+		var resp *github.Response
+		var err error
+		prDetail, resp, err = g.client.PullRequests.Get(ctx, owner, repo, review.PRNumber)
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
+	review.Additions = prDetail.GetAdditions()
+	review.Deletions = prDetail.GetDeletions()
+	review.ChangedFiles = prDetail.GetChangedFiles()
+
+	submitted, err := listAll(&g.limiter, func(opts github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+		return g.client.PullRequests.ListReviews(ctx, owner, repo, review.PRNumber, &opts)
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range submitted {
+		if !strings.EqualFold(r.GetUser().GetLogin(), run.username) || r.SubmittedAt == nil {
+			continue
+		}
+		if !run.inPeriod(r.SubmittedAt.Time) {
+			continue
+		}
+		review.Reviews = append(review.Reviews, ReviewSubmission{
+			State:       r.GetState(),
+			SubmittedAt: r.SubmittedAt.Time,
+		})
+	}
+	sort.Slice(review.Reviews, func(a, b int) bool {
+		return review.Reviews[a].SubmittedAt.Before(review.Reviews[b].SubmittedAt)
+	})
+
+	comments, err := listAll(&g.limiter, func(opts github.ListOptions) ([]*github.PullRequestComment, *github.Response, error) {
+		return g.client.PullRequests.ListComments(ctx, owner, repo, review.PRNumber, &github.PullRequestListCommentsOptions{ListOptions: opts})
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if strings.EqualFold(c.GetUser().GetLogin(), run.username) && c.CreatedAt != nil && run.inPeriod(c.CreatedAt.Time) {
+			review.InlineComments++
+		}
+	}
+
+	return nil
+}
+
+// fetchReviewRequests looks up when the associate was requested as a reviewer
+// on each pull request, using the pull request timeline. Reviews that cannot be
+// looked up are left without a request time, with EnrichError set.
+func (g *GitHubClient) fetchReviewRequests(ctx context.Context, run *fetchRun, reviews []CodeReview) {
+	forEachConcurrently(g.opts.Concurrency, len(reviews), func(i int) {
+		if len(reviews[i].Reviews) == 0 {
+			return
+		}
+		requestedAt, err := g.reviewRequestedAt(ctx, run, reviews[i])
+		if err != nil {
+			log.Printf("    Warning: Error fetching timeline of %s: %v", reviews[i].URL, err)
+			if reviews[i].EnrichError == nil {
+				reviews[i].EnrichError = err
+			}
+			return
+		}
+		reviews[i].ReviewRequestedAt = requestedAt
+	})
+}
+
+// reviewRequestedAt walks the timeline of the reviewed pull request and
+// returns the pending request answered by the associate's first review in
+// the period. Requests answered by earlier reviews, and requests that were
+// withdrawn, do not count.
+func (g *GitHubClient) reviewRequestedAt(ctx context.Context, run *fetchRun, review CodeReview) (*time.Time, error) {
+	events := review.timeline
+	if events == nil {
+		var err error
+		if events, err = g.listTimeline(ctx, review.Repo, review.PRNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	var pending *time.Time
+	for _, event := range events {
+		switch event.Event {
+		case "review_requested":
+			if strings.EqualFold(event.Login, run.username) && pending == nil && event.At != nil {
+				requested := *event.At
+				pending = &requested
+			}
+		case "review_request_removed":
+			if strings.EqualFold(event.Login, run.username) {
+				pending = nil
+			}
+		case "reviewed":
+			if !strings.EqualFold(event.Login, run.username) || event.At == nil {
+				continue
+			}
+			if run.inPeriod(*event.At) {
+				return pending, nil
+			}
+			pending = nil
+		}
+	}
+
+	return nil, nil
+}
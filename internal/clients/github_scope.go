@@ -0,0 +1,118 @@
+package clients
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RepoScope limits GitHub activity to some owners and repositories. A
+// pattern is either an owner such as "kubevirt", covering all of its
+// repositories, or a repository such as "kubevirt/kubevirt". Both parts may
+// use path.Match wildcards, as in "kubevirt/*-operator". An empty Include
+// allows every repository that is not excluded.
+type RepoScope struct {
+	Include []string
+	Exclude []string
+}
+
+// maxScopeQualifiers bounds the qualifiers added to a search query, which
+// GitHub limits to 256 characters. Larger scopes are applied as a filter.
+const maxScopeQualifiers = 5
+
+// IsZero reports whether the scope allows every repository.
+func (s RepoScope) IsZero() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+// String describes the scope for the report.
+func (s RepoScope) String() string {
+	if s.IsZero() {
+		return "all repositories"
+	}
+	description := "all repositories"
+	if len(s.Include) > 0 {
+		description = strings.Join(s.Include, ", ")
+	}
+	if len(s.Exclude) > 0 {
+		description += " excluding " + strings.Join(s.Exclude, ", ")
+	}
+	return description
+}
+
+func (s RepoScope) validate() error {
+	for _, pattern := range append(append([]string{}, s.Include...), s.Exclude...) {
+		if pattern == "" || strings.Count(pattern, "/") > 1 {
+			return fmt.Errorf("invalid repository pattern %q (must be \"owner\" or \"owner/repo\")", pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether repo, in "owner/repo" form, is in scope.
+func (s RepoScope) Allows(repo string) bool {
+	if matchesRepoPattern(repo, s.Exclude) {
+		return false
+	}
+	return len(s.Include) == 0 || matchesRepoPattern(repo, s.Include)
+}
+
+func matchesRepoPattern(repo string, patterns []string) bool {
+	owner, _ := splitRepoName(repo)
+	for _, pattern := range patterns {
+		subject := repo
+		if !strings.Contains(pattern, "/") {
+			subject = owner
+		}
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(subject)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// qualifiers returns search qualifiers that narrow a search down to the
+// scope. Patterns with wildcards cannot be expressed as qualifiers, and
+// neither can a long list, so those are left to Allows; as the results are
+// always filtered with Allows, the qualifiers only save fetching results
+// that would be dropped. Includes are only turned into qualifiers when they
+// are all owners or all repositories, as GitHub only ORs repeated
+// qualifiers of the same kind.
+func (s RepoScope) qualifiers() string {
+	var qualifiers []string
+	includable := len(s.Include) <= maxScopeQualifiers
+	for _, pattern := range s.Include {
+		if strings.ContainsAny(pattern, "*?[\\") || strings.Contains(pattern, "/") != strings.Contains(s.Include[0], "/") {
+			includable = false
+		}
+	}
+	if includable {
+		for _, pattern := range s.Include {
+			qualifiers = append(qualifiers, scopeQualifier(pattern))
+		}
+	}
+
+	excluded := 0
+	for _, pattern := range s.Exclude {
+		if strings.ContainsAny(pattern, "*?[\\") || excluded == maxScopeQualifiers {
+			continue
+		}
+		qualifiers = append(qualifiers, "-"+scopeQualifier(pattern))
+		excluded++
+	}
+
+	if len(qualifiers) == 0 {
+		return ""
+	}
+	return " " + strings.Join(qualifiers, " ")
+}
+
+func scopeQualifier(pattern string) string {
+	if strings.Contains(pattern, "/") {
+		return "repo:" + pattern
+	}
+	return "org:" + pattern
+}
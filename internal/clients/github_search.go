@@ -0,0 +1,163 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// searchResultCap is the maximum number of results the search API returns
+// for a single query, however many match.
+const searchResultCap = 1000
+
+// fetchRun carries the parameters and bookkeeping of one FetchContributions
+// call.
+type fetchRun struct {
+	username  string
+	startDate time.Time
+	endDate   time.Time
+	// scope limits the repositories whose activity is collected.
+	scope RepoScope
+	// capped collects the searches that were truncated by the search API.
+	capped []string
+}
+
+// inPeriod reports whether t falls in the run's date range.
+func (run *fetchRun) inPeriod(t time.Time) bool {
+	return !t.Before(run.startDate) && !t.After(run.endDate)
+}
+
+// searchPage fetches one page of search results for query, together with the
+// number of matches and whether GitHub timed out before finding them all.
+type searchPage[T any] func(ctx context.Context, query string, opts *github.SearchOptions) (results []T, total int, incomplete bool, resp *github.Response, err error)
+
+// searchIssuesByRange returns every result of the search built by query for
+// the run's date range. When a range matches more than searchResultCap
+// results it is split in two and each half searched on its own, down to
+// single days. A single day that is still over the cap is recorded in
+// run.capped and only its first searchResultCap results are returned.
+// Results outside the run's scope are dropped.
+func (g *GitHubClient) searchIssuesByRange(ctx context.Context, run *fetchRun, query func(dateRange string) string) ([]*github.Issue, error) {
+	results, err := searchByRange(ctx, run, g.searchIssuesPage, query)
+	if err != nil {
+		return nil, err
+	}
+	return inScope(run, results, issueRepo), nil
+}
+
+// searchCommitsByRange is searchIssuesByRange for the commit search API.
+func (g *GitHubClient) searchCommitsByRange(ctx context.Context, run *fetchRun, query func(dateRange string) string) ([]*github.CommitResult, error) {
+	results, err := searchByRange(ctx, run, g.searchCommitsPage, query)
+	if err != nil {
+		return nil, err
+	}
+	return inScope(run, results, func(result *github.CommitResult) string {
+		return result.GetRepository().GetFullName()
+	}), nil
+}
+
+// inScope keeps the results whose repository is in the run's scope.
+func inScope[T any](run *fetchRun, results []T, repoOf func(T) string) []T {
+	if run.scope.IsZero() {
+		return results
+	}
+	var kept []T
+	for _, result := range results {
+		if run.scope.Allows(repoOf(result)) {
+			kept = append(kept, result)
+		}
+	}
+	return kept
+}
+
+// searchByRange implements searchIssuesByRange for any search API, adding the
+// run's scope qualifiers to every query.
+func searchByRange[T any](ctx context.Context, run *fetchRun, page searchPage[T], query func(dateRange string) string) ([]T, error) {
+	start := truncateToDay(run.startDate)
+	end := truncateToDay(run.endDate)
+	return searchInRange(ctx, run, page, query, start, end)
+}
+
+func searchInRange[T any](ctx context.Context, run *fetchRun, page searchPage[T], query func(dateRange string) string, start, end time.Time) ([]T, error) {
+	q := query(start.Format("2006-01-02")+".."+end.Format("2006-01-02")) + run.scope.qualifiers()
+	opts := &github.SearchOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	results, total, incomplete, resp, err := page(ctx, q, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	if total > searchResultCap && days > 1 {
+		mid := start.AddDate(0, 0, days/2)
+		first, err := searchInRange(ctx, run, page, query, start, mid.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		second, err := searchInRange(ctx, run, page, query, mid, end)
+		if err != nil {
+			return nil, err
+		}
+		return append(first, second...), nil
+	}
+
+	if total > searchResultCap {
+		run.capped = append(run.capped, fmt.Sprintf("%q matched %d results; only the first %d were fetched", q, total, searchResultCap))
+	} else if incomplete {
+		run.capped = append(run.capped, fmt.Sprintf("%q timed out on GitHub and returned incomplete results", q))
+	}
+
+	allResults := results
+	for resp.NextPage != 0 {
+		opts.Page = resp.NextPage
+		results, _, _, resp, err = page(ctx, q, opts)
+		if err != nil {
+			return nil, err
+		}
+		allResults = append(allResults, results...)
+	}
+
+	return allResults, nil
+}
+
+// searchIssuesPage fetches one page of issue search results, waiting out
+// rate limits.
+func (g *GitHubClient) searchIssuesPage(ctx context.Context, query string, opts *github.SearchOptions) ([]*github.Issue, int, bool, *github.Response, error) {
+	var result *github.IssuesSearchResult
+	var resp *github.Response
+	err := g.limiter.call(func() (*github.Response, error) {
+		var err error
+		result, resp, err = g.client.Search.Issues(ctx, query, opts)
+		return resp, err
+	})
+	if err != nil {
+		return nil, 0, false, resp, err
+	}
+
+	return result.Issues, result.GetTotal(), result.GetIncompleteResults(), resp, nil
+}
+
+// searchCommitsPage fetches one page of commit search results, waiting out
+// rate limits.
+func (g *GitHubClient) searchCommitsPage(ctx context.Context, query string, opts *github.SearchOptions) ([]*github.CommitResult, int, bool, *github.Response, error) {
+	var result *github.CommitsSearchResult
+	var resp *github.Response
+	err := g.limiter.call(func() (*github.Response, error) {
+		var err error
+		result, resp, err = g.client.Search.Commits(ctx, query, opts)
+		return resp, err
+	})
+	if err != nil {
+		return nil, 0, false, resp, err
+	}
+
+	return result.Commits, result.GetTotal(), result.GetIncompleteResults(), resp, nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,83 @@
+package clients
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// fakeSearch is a searchPage that answers "created:<start>..<end>" queries
+// with perDay matches for every day in the range, or with the count in
+// days for the days listed there. Each page holds the query as its only
+// result, so the results tell which ranges were searched.
+func fakeSearch(perDay int, days map[string]int) searchPage[string] {
+	return func(ctx context.Context, query string, opts *github.SearchOptions) ([]string, int, bool, *github.Response, error) {
+		dates := strings.SplitN(strings.TrimPrefix(query, "created:"), "..", 2)
+		start, _ := time.Parse("2006-01-02", dates[0])
+		end, _ := time.Parse("2006-01-02", dates[1])
+
+		total := 0
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if n, ok := days[day.Format("2006-01-02")]; ok {
+				total += n
+			} else {
+				total += perDay
+			}
+		}
+		return []string{query}, total, false, &github.Response{}, nil
+	}
+}
+
+func createdQuery(dateRange string) string {
+	return "created:" + dateRange
+}
+
+func TestSearchInRangeSplitsDownToDays(t *testing.T) {
+	run := &fetchRun{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	results, err := searchInRange(context.Background(), run, fakeSearch(600, map[string]int{"2024-01-05": 1500}), createdQuery, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Any two days are over the cap, so every day is searched on its own,
+	// in order.
+	if len(results) != 10 {
+		t.Fatalf("got %d searches, want 10: %v", len(results), results)
+	}
+	for i, query := range results {
+		day := start.AddDate(0, 0, i).Format("2006-01-02")
+		if want := "created:" + day + ".." + day; query != want {
+			t.Errorf("search %d = %q, want %q", i, query, want)
+		}
+	}
+
+	if len(run.capped) != 1 {
+		t.Fatalf("got capped searches %q, want one for 2024-01-05", run.capped)
+	}
+	if want := `"created:2024-01-05..2024-01-05" matched 1500 results`; !strings.HasPrefix(run.capped[0], want) {
+		t.Errorf("capped search = %q, want prefix %q", run.capped[0], want)
+	}
+}
+
+func TestSearchInRangeKeepsRangeUnderCap(t *testing.T) {
+	run := &fetchRun{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	results, err := searchInRange(context.Background(), run, fakeSearch(10, nil), createdQuery, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"created:2024-01-01..2024-03-31"}; len(results) != 1 || results[0] != want[0] {
+		t.Errorf("got searches %q, want %q", results, want)
+	}
+	if len(run.capped) != 0 {
+		t.Errorf("got capped searches %q, want none", run.capped)
+	}
+}
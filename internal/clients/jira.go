@@ -4,17 +4,94 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// jiraPageSize is the page size requested from the search API. Many Data
+// Center instances cap it lower (often 50), which the paging loop tolerates.
+const jiraPageSize = 100
+
+// DefaultStoryPointsField is the story points custom field used when none is
+// configured. Custom field IDs differ between Jira instances, so most setups
+// need to override it (see JiraClient.DiscoverFields).
+const DefaultStoryPointsField = "customfield_12310243"
+
+// Jira authentication modes.
+const (
+	// JiraAuthPAT sends a Personal Access Token as a bearer token and uses the
+	// v2 REST API (Jira Data Center/Server).
+	JiraAuthPAT = "pat"
+	// JiraAuthCloud sends an account email and API token with basic auth and
+	// uses the v3 REST API (Jira Cloud).
+	JiraAuthCloud = "cloud"
+)
+
+// DefaultDoneStatuses are the workflow statuses treated as completed work
+// when none are configured.
+var DefaultDoneStatuses = []string{"Done", "Closed", "Resolved"}
+
+// DefaultInProgressStatuses are the statuses that start the cycle time clock
+// when none are configured.
+var DefaultInProgressStatuses = []string{"In Progress"}
+
 type JiraClient struct {
 	baseURL string
 	token   string
+	opts    JiraOptions
 	client  *http.Client
 }
 
+// JiraOptions holds the instance-specific settings of a Jira server.
+type JiraOptions struct {
+	// AuthMode is JiraAuthPAT (default) or JiraAuthCloud.
+	AuthMode string
+	// Email is the account email used with an API token on Jira Cloud.
+	Email string
+	// StoryPointsField is the ID of the custom field holding story points.
+	StoryPointsField string
+	// DoneStatuses are the workflow statuses that count as completed work.
+	DoneStatuses []string
+	// ExcludedResolutions are resolutions (e.g. Duplicate) that do not count
+	// as completed work even when the issue reached a done status.
+	ExcludedResolutions []string
+	// ExtraJQL is an optional clause ANDed into every search, such as a
+	// project filter.
+	ExtraJQL string
+	// FlowMetrics requests each issue's changelog to compute cycle time,
+	// lead time and time in status.
+	FlowMetrics bool
+	// InProgressStatuses are the statuses that mark work as started for
+	// cycle time purposes.
+	InProgressStatuses []string
+	// SprintField is the ID of the sprint custom field. Sprints are not
+	// collected when it is empty.
+	SprintField string
+	// EpicLinkField is the ID of the "Epic Link" custom field. When empty,
+	// only the parent field (used by newer Jira versions) links epics.
+	EpicLinkField string
+	// RemoteLinks requests the remote links of each completed issue, so
+	// that pull requests linked by the Jira GitHub integration are found
+	// even when they do not mention the issue key.
+	RemoteLinks bool
+}
+
+// JiraField describes a field as returned by /rest/api/2/field.
+type JiraField struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+	Schema struct {
+		Type   string `json:"type"`
+		Custom string `json:"custom"`
+	} `json:"schema"`
+}
+
 type JiraIssue struct {
 	Key            string `json:"key"`
 	Summary        string
@@ -28,128 +105,546 @@ type JiraIssue struct {
 	Created        time.Time
 	Updated        time.Time
 	Resolved       time.Time
+	// UserComments is the number of comments the associate left on the issue
+	// in the report window; only set for commented issues.
+	UserComments int
+	// Flow is only set when flow metrics are enabled.
+	Flow *JiraFlow
+	// Sprints lists the names of the sprints the issue was part of.
+	Sprints  []string
+	EpicKey  string
+	EpicName string
+	// Project is the project key, ProjectName its display name.
+	Project     string
+	ProjectName string
+	Labels      []string
+	Components  []string
+	FixVersions []string
+	// RemoteLinks are the URLs the issue links to, such as pull requests;
+	// only set for completed issues.
+	RemoteLinks []string
 }
 
 type jiraSearchResponse struct {
-	Issues []struct {
-		Key    string `json:"key"`
-		Fields struct {
-			Summary string `json:"summary"`
-			Status  struct {
-				Name string `json:"name"`
-			} `json:"status"`
-			IssueType struct {
-				Name string `json:"name"`
-			} `json:"issuetype"`
-			Priority struct {
-				Name string `json:"name"`
-			} `json:"priority"`
-			Assignee struct {
-				DisplayName string `json:"displayName"`
-			} `json:"assignee"`
-			Reporter struct {
-				DisplayName string `json:"displayName"`
-			} `json:"reporter"`
-			StoryPoints      interface{} `json:"customfield_12310243"` // Common story points field
-			Created          string      `json:"created"`
-			Updated          string      `json:"updated"`
-			ResolutionDate   string      `json:"resolutiondate"`
-		} `json:"fields"`
-	} `json:"issues"`
-	Total int `json:"total"`
-}
-
-func NewJiraClient(baseURL, token string) *JiraClient {
+	Issues     []jiraSearchIssue `json:"issues"`
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+}
+
+// jiraCloudSearchResponse is a page of the Jira Cloud /rest/api/3/search/jql
+// endpoint, which pages with an opaque token and reports no total.
+type jiraCloudSearchResponse struct {
+	Issues        []jiraSearchIssue `json:"issues"`
+	NextPageToken string            `json:"nextPageToken"`
+	IsLast        bool              `json:"isLast"`
+}
+
+type jiraSearchIssue struct {
+	Key    string
+	Fields jiraIssueFields
+	// RawFields holds every returned field by ID, so that custom fields whose
+	// IDs are only known at runtime can be looked up.
+	RawFields map[string]json.RawMessage
+	// Changelog is only present when the search asked for expand=changelog.
+	Changelog *jiraChangelog
+}
+
+type jiraIssueFields struct {
+	Summary string `json:"summary"`
+	Status  struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	IssueType struct {
+		Name string `json:"name"`
+	} `json:"issuetype"`
+	Priority struct {
+		Name string `json:"name"`
+	} `json:"priority"`
+	Assignee    jiraUser        `json:"assignee"`
+	Reporter    jiraUser        `json:"reporter"`
+	Comment     jiraCommentPage `json:"comment"`
+	Parent      *jiraParent     `json:"parent"`
+	Labels      []string        `json:"labels"`
+	Components  []jiraNamed     `json:"components"`
+	FixVersions []jiraNamed     `json:"fixVersions"`
+	Project     struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Created        string `json:"created"`
+	Updated        string `json:"updated"`
+	ResolutionDate string `json:"resolutiondate"`
+}
+
+// jiraNamed is any Jira entity referenced by name, such as a component or
+// version.
+type jiraNamed struct {
+	Name string `json:"name"`
+}
+
+func jiraNames(values []jiraNamed) []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// jiraUser identifies a user on either deployment: Data Center/Server uses
+// name and key, Jira Cloud uses accountId.
+type jiraUser struct {
+	Name         string `json:"name"`
+	Key          string `json:"key"`
+	AccountID    string `json:"accountId"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+}
+
+// is reports whether u is the user configured as username.
+func (u jiraUser) is(username string) bool {
+	for _, id := range []string{u.Name, u.Key, u.AccountID, u.EmailAddress} {
+		if id != "" && strings.EqualFold(id, username) {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *jiraSearchIssue) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Key       string          `json:"key"`
+		Fields    json.RawMessage `json:"fields"`
+		Changelog *jiraChangelog  `json:"changelog"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	i.Key = raw.Key
+	i.Changelog = raw.Changelog
+	if len(raw.Fields) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Fields, &i.Fields); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw.Fields, &i.RawFields)
+}
+
+func NewJiraClient(baseURL, token string, opts JiraOptions) (*JiraClient, error) {
+	switch opts.AuthMode {
+	case "":
+		opts.AuthMode = JiraAuthPAT
+	case JiraAuthPAT:
+	case JiraAuthCloud:
+		if opts.Email == "" {
+			return nil, fmt.Errorf("jira auth mode %q requires an email", JiraAuthCloud)
+		}
+	default:
+		return nil, fmt.Errorf("unknown jira auth mode %q (must be %q or %q)", opts.AuthMode, JiraAuthPAT, JiraAuthCloud)
+	}
+	if opts.StoryPointsField == "" {
+		opts.StoryPointsField = DefaultStoryPointsField
+	}
+	if len(opts.DoneStatuses) == 0 {
+		opts.DoneStatuses = DefaultDoneStatuses
+	}
+	if len(opts.InProgressStatuses) == 0 {
+		opts.InProgressStatuses = DefaultInProgressStatuses
+	}
+
 	return &JiraClient{
-		baseURL: baseURL,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
 		token:   token,
+		opts:    opts,
 		client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// JiraData holds the Jira activity collected for an associate.
+type JiraData struct {
+	Issues []JiraIssue
+	// ServerTotal is the number of issues Jira reported as matching the
+	// search, which may exceed len(Issues) if paging stopped early.
+	ServerTotal int
+	// UnparsedDates lists "KEY (field)" entries whose timestamps could not be
+	// parsed and were left zero.
+	UnparsedDates []string
+
+	// Activity beyond being the assignee at resolution time
+	ReportedIssues         []JiraIssue
+	CommentedIssues        []JiraIssue
+	FormerlyAssignedIssues []JiraIssue
+
+	// Worklogs is the time the associate logged in the period, on any issue.
+	Worklogs []JiraWorklog
+
+	// Warnings describe data that could not be fetched. The rest of the data
+	// is still complete.
+	Warnings []string
+}
+
+func (d *JiraData) addWarning(format string, args ...interface{}) {
+	d.Warnings = append(d.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (d *JiraData) addUnparsed(key string, fields []string) {
+	for _, field := range fields {
+		d.UnparsedDates = append(d.UnparsedDates, fmt.Sprintf("%s (%s)", key, field))
 	}
 }
 
-func (j *JiraClient) FetchCompletedIssues(username string, startDate, endDate time.Time) ([]JiraIssue, error) {
+// Incomplete reports whether Jira returned fewer issues than it said exist.
+func (d *JiraData) Incomplete() bool {
+	return len(d.Issues) < d.ServerTotal
+}
+
+func (j *JiraClient) FetchCompletedIssues(username string, startDate, endDate time.Time) (*JiraData, error) {
 	// JQL query to find issues completed by the user in the date range
-	jql := fmt.Sprintf(
-		`assignee = "%s" AND status in (Done, Closed, Resolved) AND resolved >= "%s" AND resolved <= "%s" ORDER BY resolved DESC`,
-		username,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
+	clauses := []string{
+		"assignee = " + jqlString(username),
+		"status in " + jqlList(j.opts.DoneStatuses),
+	}
+	if len(j.opts.ExcludedResolutions) > 0 {
+		clauses = append(clauses, "(resolution is EMPTY OR resolution not in "+jqlList(j.opts.ExcludedResolutions)+")")
+	}
+	clauses = append(clauses,
+		"resolved >= "+jqlString(startDate.Format("2006-01-02")),
+		"resolved <= "+jqlString(endDate.Format("2006-01-02")),
 	)
+	jql := j.withExtraJQL(strings.Join(clauses, " AND ")) + " ORDER BY resolved DESC"
 
-	params := url.Values{}
-	params.Add("jql", jql)
-	params.Add("maxResults", "1000")
-	params.Add("fields", "summary,status,issuetype,priority,assignee,reporter,customfield_12310243,created,updated,resolutiondate")
+	fields := j.issueFields()
 
-	apiURL := fmt.Sprintf("%s/rest/api/2/search?%s", j.baseURL, params.Encode())
+	expand := ""
+	if j.opts.FlowMetrics {
+		expand = "changelog"
+	}
+
+	data := &JiraData{Issues: []JiraIssue{}}
+	// Flow metrics are optional, so issues whose changelog cannot be fetched
+	// are kept without them.
+	var noFlow []string
+	total, err := j.searchIssues(jql, fields, expand, func(issue jiraSearchIssue) {
+		converted, badFields := j.convertIssue(issue)
+		if j.opts.FlowMetrics {
+			flow, badHistory, err := j.issueFlow(issue, converted)
+			if err != nil {
+				log.Printf("    Warning: Error fetching changelog of %s: %v", issue.Key, err)
+				noFlow = append(noFlow, issue.Key)
+			}
+			converted.Flow = flow
+			if badHistory {
+				badFields = append(badFields, "changelog")
+			}
+		}
+		data.Issues = append(data.Issues, converted)
+		data.addUnparsed(issue.Key, badFields)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(noFlow) > 0 {
+		data.addWarning("Changelogs of some Jira issues could not be fetched, so they have no flow metrics: %s.", strings.Join(noFlow, ", "))
+	}
+	data.ServerTotal = total
+
+	j.resolveEpicNames(data)
+
+	if j.opts.RemoteLinks {
+		j.fetchRemoteLinks(data)
+	}
+
+	return data, nil
+}
+
+// issueFields lists the fields needed to fill in a JiraIssue.
+func (j *JiraClient) issueFields() []string {
+	fields := []string{"summary", "status", "issuetype", "priority", "assignee", "reporter", j.opts.StoryPointsField, "created", "updated", "resolutiondate", "parent", "labels", "components", "fixVersions", "project"}
+	if j.opts.SprintField != "" {
+		fields = append(fields, j.opts.SprintField)
+	}
+	if j.opts.EpicLinkField != "" {
+		fields = append(fields, j.opts.EpicLinkField)
+	}
+	return fields
+}
+
+// searchIssues runs jql and calls fn for every matching issue, following the
+// paging scheme of the configured deployment. It returns the number of issues
+// the server reported as matching.
+func (j *JiraClient) searchIssues(jql string, fields []string, expand string, fn func(jiraSearchIssue)) (int, error) {
+	if j.opts.AuthMode == JiraAuthCloud {
+		return j.searchIssuesCloud(jql, fields, expand, fn)
+	}
+
+	fetched := 0
+	total := 0
+	for {
+		params := url.Values{}
+		params.Add("jql", jql)
+		params.Add("startAt", strconv.Itoa(fetched))
+		params.Add("maxResults", strconv.Itoa(jiraPageSize))
+		params.Add("fields", strings.Join(fields, ","))
+		if expand != "" {
+			params.Add("expand", expand)
+		}
+
+		var searchResp jiraSearchResponse
+		if err := j.get("/rest/api/2/search", params, &searchResp); err != nil {
+			return 0, err
+		}
+
+		for _, issue := range searchResp.Issues {
+			fn(issue)
+		}
+		total = searchResp.Total
+
+		// The server may cap maxResults below what we asked for, so advance by
+		// what was actually returned rather than by the requested page size.
+		fetched += len(searchResp.Issues)
+		fmt.Printf("  - Fetched %d/%d issues...\n", fetched, total)
+
+		if len(searchResp.Issues) == 0 || fetched >= total {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+// searchIssuesCloud pages through /rest/api/3/search/jql. That endpoint has
+// no total count, so the total is whatever was fetched once the last page
+// has been reached.
+func (j *JiraClient) searchIssuesCloud(jql string, fields []string, expand string, fn func(jiraSearchIssue)) (int, error) {
+	fetched := 0
+	nextPageToken := ""
+	for {
+		params := url.Values{}
+		params.Add("jql", jql)
+		params.Add("maxResults", strconv.Itoa(jiraPageSize))
+		params.Add("fields", strings.Join(fields, ","))
+		if expand != "" {
+			params.Add("expand", expand)
+		}
+		if nextPageToken != "" {
+			params.Add("nextPageToken", nextPageToken)
+		}
+
+		var searchResp jiraCloudSearchResponse
+		if err := j.get("/rest/api/3/search/jql", params, &searchResp); err != nil {
+			return 0, err
+		}
+
+		for _, issue := range searchResp.Issues {
+			fn(issue)
+		}
+		fetched += len(searchResp.Issues)
+		fmt.Printf("  - Fetched %d issues...\n", fetched)
+
+		if searchResp.IsLast || searchResp.NextPageToken == "" || len(searchResp.Issues) == 0 {
+			break
+		}
+		nextPageToken = searchResp.NextPageToken
+	}
+
+	return fetched, nil
+}
+
+// withExtraJQL ANDs the configured extra clause into jql. The clause is
+// parenthesized so an OR inside it cannot widen the rest of the query.
+func (j *JiraClient) withExtraJQL(jql string) string {
+	extra := strings.TrimSpace(j.opts.ExtraJQL)
+	if extra == "" {
+		return jql
+	}
+	return jql + " AND (" + extra + ")"
+}
+
+// jqlString quotes s as a JQL string literal.
+func jqlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// jqlList formats values as a parenthesized list of JQL string literals.
+func jqlList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = jqlString(v)
+	}
+	return "(" + strings.Join(quoted, ", ") + ")"
+}
+
+// restAPI returns the path prefix of the REST API version used by the
+// configured deployment.
+func (j *JiraClient) restAPI() string {
+	if j.opts.AuthMode == JiraAuthCloud {
+		return "/rest/api/3"
+	}
+	return "/rest/api/2"
+}
+
+// get performs an authenticated GET against the Jira REST API and decodes the
+// JSON response into out.
+func (j *JiraClient) get(path string, params url.Values, out interface{}) error {
+	apiURL := j.baseURL + path
+	if len(params) > 0 {
+		apiURL += "?" + params.Encode()
+	}
 
 	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return fmt.Errorf("creating request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+j.token)
+	if j.opts.AuthMode == JiraAuthCloud {
+		req.SetBasicAuth(j.opts.Email, j.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := j.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+		return fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
+		return fmt.Errorf("reading response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Jira API error (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("Jira API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
-	var searchResp jiraSearchResponse
-	if err := json.Unmarshal(body, &searchResp); err != nil {
+	if err := json.Unmarshal(body, out); err != nil {
 		// Show the first 200 characters of the response to help debug
 		preview := string(body)
 		if len(preview) > 200 {
 			preview = preview[:200] + "..."
 		}
-		return nil, fmt.Errorf("decoding JSON response: %w\nResponse preview: %s", err, preview)
+		return fmt.Errorf("decoding JSON response: %w\nResponse preview: %s", err, preview)
 	}
 
-	issues := make([]JiraIssue, 0, len(searchResp.Issues))
-	for _, issue := range searchResp.Issues {
-		created, _ := time.Parse(time.RFC3339, issue.Fields.Created)
-		updated, _ := time.Parse(time.RFC3339, issue.Fields.Updated)
-		resolved, _ := time.Parse(time.RFC3339, issue.Fields.ResolutionDate)
+	return nil
+}
 
-		// Parse story points - could be float64 or nil
-		storyPoints := 0.0
-		hasStoryPoints := false
-		if issue.Fields.StoryPoints != nil {
-			hasStoryPoints = true
-			switch v := issue.Fields.StoryPoints.(type) {
-			case float64:
-				storyPoints = v
-			case int:
-				storyPoints = float64(v)
-			}
+// JiraFieldCandidates groups the fields that may be the instance-specific
+// custom fields configured in JiraOptions.
+type JiraFieldCandidates struct {
+	StoryPoints []JiraField
+	Sprint      []JiraField
+	EpicLink    []JiraField
+}
+
+// DiscoverFields lists the fields that look like the story points, sprint and
+// epic link fields. Story points candidates are ordered with number fields
+// first since those are the usable ones.
+func (j *JiraClient) DiscoverFields() (*JiraFieldCandidates, error) {
+	var fields []JiraField
+	if err := j.get("/rest/api/2/field", nil, &fields); err != nil {
+		return nil, err
+	}
+
+	candidates := &JiraFieldCandidates{}
+	for _, field := range fields {
+		name := strings.ToLower(field.Name)
+		switch {
+		case strings.Contains(name, "story point") || strings.Contains(name, "storypoint"):
+			candidates.StoryPoints = append(candidates.StoryPoints, field)
+		case field.Schema.Custom == jiraSprintFieldType || name == "sprint":
+			candidates.Sprint = append(candidates.Sprint, field)
+		case field.Schema.Custom == jiraEpicLinkFieldType || name == "epic link":
+			candidates.EpicLink = append(candidates.EpicLink, field)
+		}
+	}
+
+	sort.SliceStable(candidates.StoryPoints, func(a, b int) bool {
+		return candidates.StoryPoints[a].Schema.Type == "number" && candidates.StoryPoints[b].Schema.Type != "number"
+	})
+
+	return candidates, nil
+}
+
+// jiraTimeLayouts are the timestamp formats Jira is known to return. Server
+// and Data Center use "2024-03-05T14:22:10.000+0000", which RFC 3339 rejects
+// because the offset has no colon. Fractional seconds are optional in all of
+// them, since Go accepts a fraction after the seconds field when parsing.
+var jiraTimeLayouts = []string{
+	"2006-01-02T15:04:05Z0700",
+	time.RFC3339,
+	"2006-01-02T15:04Z0700",
+	"2006-01-02",
+}
+
+// parseJiraTime parses a Jira timestamp, keeping its original UTC offset. An
+// empty string is a valid zero time, as returned for unresolved issues.
+func parseJiraTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range jiraTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
 		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized Jira timestamp %q", s)
+}
+
+// convertIssue maps a search result onto JiraIssue. It also returns the names
+// of the date fields that could not be parsed.
+func (j *JiraClient) convertIssue(issue jiraSearchIssue) (JiraIssue, []string) {
+	var badFields []string
+	parseField := func(name, value string) time.Time {
+		t, err := parseJiraTime(value)
+		if err != nil {
+			badFields = append(badFields, name)
+		}
+		return t
+	}
 
-		issues = append(issues, JiraIssue{
-			Key:            issue.Key,
-			Summary:        issue.Fields.Summary,
-			Status:         issue.Fields.Status.Name,
-			Type:           issue.Fields.IssueType.Name,
-			Priority:       issue.Fields.Priority.Name,
-			StoryPoints:    storyPoints,
-			HasStoryPoints: hasStoryPoints,
-			Assignee:       issue.Fields.Assignee.DisplayName,
-			Reporter:       issue.Fields.Reporter.DisplayName,
-			Created:        created,
-			Updated:        updated,
-			Resolved:       resolved,
-		})
+	created := parseField("created", issue.Fields.Created)
+	updated := parseField("updated", issue.Fields.Updated)
+	resolved := parseField("resolutiondate", issue.Fields.ResolutionDate)
+
+	// Parse story points - a number, or null when not estimated
+	storyPoints := 0.0
+	hasStoryPoints := false
+	if raw, ok := issue.RawFields[j.opts.StoryPointsField]; ok {
+		var value *float64
+		if err := json.Unmarshal(raw, &value); err == nil && value != nil {
+			storyPoints = *value
+			hasStoryPoints = true
+		}
 	}
 
-	return issues, nil
+	epicKey, epicName := j.issueEpic(issue)
+
+	return JiraIssue{
+		Key:            issue.Key,
+		Summary:        issue.Fields.Summary,
+		Status:         issue.Fields.Status.Name,
+		Type:           issue.Fields.IssueType.Name,
+		Priority:       issue.Fields.Priority.Name,
+		StoryPoints:    storyPoints,
+		HasStoryPoints: hasStoryPoints,
+		Assignee:       issue.Fields.Assignee.DisplayName,
+		Reporter:       issue.Fields.Reporter.DisplayName,
+		Created:        created,
+		Updated:        updated,
+		Resolved:       resolved,
+		Sprints:        j.sprintNames(issue),
+		EpicKey:        epicKey,
+		EpicName:       epicName,
+		Project:        issue.Fields.Project.Key,
+		ProjectName:    issue.Fields.Project.Name,
+		Labels:         issue.Fields.Labels,
+		Components:     jiraNames(issue.Fields.Components),
+		FixVersions:    jiraNames(issue.Fields.FixVersions),
+	}, badFields
 }
@@ -0,0 +1,168 @@
+package clients
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type jiraComment struct {
+	Author  jiraUser `json:"author"`
+	Created string   `json:"created"`
+}
+
+// jiraCommentPage is the "comment" field of an issue, which has the same
+// shape as a page of /rest/api/{2,3}/issue/{key}/comment.
+type jiraCommentPage struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Comments   []jiraComment `json:"comments"`
+}
+
+// FetchContributions collects the completed issues of an associate together
+// with the issues they reported, commented on or were formerly assigned to,
+// and the time they logged. Only the completed issues are required; if any
+// of the rest cannot be fetched, for example because the server does not
+// support updatedBy(), it is left empty and a warning is recorded in
+// JiraData.Warnings.
+func (j *JiraClient) FetchContributions(username string, startDate, endDate time.Time) (*JiraData, error) {
+	fmt.Println("  - Fetching completed issues...")
+	data, err := j.FetchCompletedIssues(username, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("fetching completed issues: %w", err)
+	}
+
+	start := jqlString(startDate.Format("2006-01-02"))
+	end := jqlString(endDate.Format("2006-01-02"))
+
+	fmt.Println("  - Fetching reported issues...")
+	reportedJQL := fmt.Sprintf("reporter = %s AND created >= %s AND created <= %s", jqlString(username), start, end)
+	data.ReportedIssues, err = j.fetchActivityIssues(data, j.withExtraJQL(reportedJQL)+" ORDER BY created DESC", nil)
+	if err != nil {
+		data.addWarning("Reported Jira issues could not be fetched: %v", err)
+	}
+
+	fmt.Println("  - Fetching commented issues...")
+	data.CommentedIssues, err = j.fetchCommentedIssues(data, username, startDate, endDate)
+	if err != nil {
+		data.addWarning("Commented Jira issues could not be fetched: %v", err)
+	}
+
+	fmt.Println("  - Fetching formerly assigned issues...")
+	formerJQL := fmt.Sprintf("assignee WAS %s DURING (%s, %s) AND (assignee != %s OR assignee is EMPTY)",
+		jqlString(username), start, end, jqlString(username))
+	data.FormerlyAssignedIssues, err = j.fetchActivityIssues(data, j.withExtraJQL(formerJQL)+" ORDER BY updated DESC", nil)
+	if err != nil {
+		data.addWarning("Formerly assigned Jira issues could not be fetched: %v", err)
+	}
+
+	fmt.Println("  - Fetching worklogs...")
+	data.Worklogs, err = j.fetchWorklogs(data, username, startDate, endDate)
+	if err != nil {
+		data.addWarning("Jira worklogs could not be fetched: %v", err)
+	}
+
+	return data, nil
+}
+
+// fetchActivityIssues runs jql and converts every result. If keep is set, it
+// decides which issues are kept and may fill in extra details.
+func (j *JiraClient) fetchActivityIssues(data *JiraData, jql string, keep func(jiraSearchIssue, *JiraIssue) (bool, error), extraFields ...string) ([]JiraIssue, error) {
+	fields := append(j.issueFields(), extraFields...)
+
+	issues := []JiraIssue{}
+	var keepErr error
+	_, err := j.searchIssues(jql, fields, "", func(issue jiraSearchIssue) {
+		if keepErr != nil {
+			return
+		}
+		converted, badFields := j.convertIssue(issue)
+		data.addUnparsed(issue.Key, badFields)
+		if keep != nil {
+			var ok bool
+			ok, keepErr = keep(issue, &converted)
+			if !ok {
+				return
+			}
+		}
+		issues = append(issues, converted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if keepErr != nil {
+		return nil, keepErr
+	}
+
+	return issues, nil
+}
+
+// fetchCommentedIssues finds the issues the user commented on in the window.
+// updatedBy() also matches field edits, so each candidate's comments are
+// checked for ones written by the user.
+func (j *JiraClient) fetchCommentedIssues(data *JiraData, username string, startDate, endDate time.Time) ([]JiraIssue, error) {
+	jql := fmt.Sprintf("issue in updatedBy(%s, %s, %s)",
+		jqlString(username),
+		jqlString(startDate.Format("2006-01-02")),
+		jqlString(endDate.Format("2006-01-02")),
+	)
+
+	keep := func(issue jiraSearchIssue, converted *JiraIssue) (bool, error) {
+		comments := issue.Fields.Comment.Comments
+		if len(comments) < issue.Fields.Comment.Total {
+			var err error
+			comments, err = j.fetchComments(issue.Key)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		badDate := false
+		for _, comment := range comments {
+			if !comment.Author.is(username) {
+				continue
+			}
+			created, err := parseJiraTime(comment.Created)
+			if err != nil {
+				badDate = true
+				continue
+			}
+			if !created.Before(startDate) && !created.After(endDate) {
+				converted.UserComments++
+			}
+		}
+		if badDate {
+			data.addUnparsed(issue.Key, []string{"comment"})
+		}
+
+		return converted.UserComments > 0, nil
+	}
+
+	return j.fetchActivityIssues(data, j.withExtraJQL(jql)+" ORDER BY updated DESC", keep, "comment")
+}
+
+// fetchComments retrieves all comments of an issue, for when the search
+// result only embedded the first page.
+func (j *JiraClient) fetchComments(key string) ([]jiraComment, error) {
+	var comments []jiraComment
+	for {
+		params := url.Values{}
+		params.Add("startAt", strconv.Itoa(len(comments)))
+		params.Add("maxResults", strconv.Itoa(jiraPageSize))
+
+		var page jiraCommentPage
+		path := j.restAPI() + "/issue/" + url.PathEscape(key) + "/comment"
+		if err := j.get(path, params, &page); err != nil {
+			return nil, err
+		}
+		comments = append(comments, page.Comments...)
+
+		if len(page.Comments) == 0 || len(comments) >= page.Total {
+			break
+		}
+	}
+
+	return comments, nil
+}
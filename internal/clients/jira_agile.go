@@ -0,0 +1,137 @@
+package clients
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Schema types of the Jira Software custom fields.
+const (
+	jiraSprintFieldType   = "com.pyxis.greenhopper.jira:gh-sprint"
+	jiraEpicLinkFieldType = "com.pyxis.greenhopper.jira:gh-epic-link"
+)
+
+// jiraParent is the parent field. On newer Jira versions and on Jira Cloud it
+// links a story to its epic, replacing the Epic Link custom field.
+type jiraParent struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary   string `json:"summary"`
+		IssueType struct {
+			Name           string `json:"name"`
+			HierarchyLevel int    `json:"hierarchyLevel"`
+		} `json:"issuetype"`
+	} `json:"fields"`
+}
+
+// jiraSprint is a sprint as returned by Jira Cloud and recent Data Center
+// versions. Older versions return a serialized Java object instead, see
+// legacySprintName.
+type jiraSprint struct {
+	Name string `json:"name"`
+}
+
+// jiraAgileEpic is the response of /rest/agile/1.0/epic/{key}.
+type jiraAgileEpic struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+}
+
+// legacySprintName matches the name in strings such as
+// "com.atlassian.greenhopper.service.sprint.Sprint@1f2e[id=12,state=CLOSED,name=Sprint 7,...]".
+var legacySprintName = regexp.MustCompile(`[\[,]name=([^,\]]*)`)
+
+// sprintNames returns the names of the sprints stored in the sprint field.
+func (j *JiraClient) sprintNames(issue jiraSearchIssue) []string {
+	raw, ok := issue.RawFields[j.opts.SprintField]
+	if !ok || j.opts.SprintField == "" {
+		return nil
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, value := range values {
+		var sprint jiraSprint
+		if err := json.Unmarshal(value, &sprint); err == nil && sprint.Name != "" {
+			names = append(names, sprint.Name)
+			continue
+		}
+		var legacy string
+		if err := json.Unmarshal(value, &legacy); err == nil {
+			if m := legacySprintName.FindStringSubmatch(legacy); m != nil {
+				names = append(names, m[1])
+			}
+		}
+	}
+
+	return names
+}
+
+// issueEpic returns the key of the issue's epic, from the Epic Link field or
+// from an epic parent. The name is only known here when it comes from the
+// parent; resolveEpicNames fills in the rest.
+func (j *JiraClient) issueEpic(issue jiraSearchIssue) (key, name string) {
+	if j.opts.EpicLinkField != "" {
+		if raw, ok := issue.RawFields[j.opts.EpicLinkField]; ok {
+			var epicKey string
+			if err := json.Unmarshal(raw, &epicKey); err == nil && epicKey != "" {
+				return epicKey, ""
+			}
+		}
+	}
+
+	if parent := issue.Fields.Parent; parent != nil {
+		issueType := parent.Fields.IssueType
+		if issueType.Name == "Epic" || issueType.HierarchyLevel == 1 {
+			return parent.Key, parent.Fields.Summary
+		}
+	}
+
+	return "", ""
+}
+
+// resolveEpicNames looks up the names of the epics linked from the issues
+// through the Agile API, once per epic. Epics that cannot be looked up, for
+// example because Jira Software is not installed, keep their key as name and
+// are listed in a warning.
+func (j *JiraClient) resolveEpicNames(data *JiraData) {
+	names := map[string]string{}
+	var failed []string
+	for i := range data.Issues {
+		issue := &data.Issues[i]
+		if issue.EpicKey == "" || issue.EpicName != "" {
+			continue
+		}
+
+		name, ok := names[issue.EpicKey]
+		if !ok {
+			var epic jiraAgileEpic
+			if err := j.get("/rest/agile/1.0/epic/"+url.PathEscape(issue.EpicKey), nil, &epic); err != nil {
+				log.Printf("    Warning: Error fetching epic %s: %v", issue.EpicKey, err)
+				failed = append(failed, issue.EpicKey)
+			} else {
+				name = epic.Name
+				if name == "" {
+					name = epic.Summary
+				}
+			}
+			if name == "" {
+				name = issue.EpicKey
+			}
+			names[issue.EpicKey] = name
+		}
+		issue.EpicName = name
+	}
+
+	if len(failed) > 0 {
+		data.addWarning("Names of some Jira epics could not be read, so they are shown by key: %s.", strings.Join(failed, ", "))
+	}
+}
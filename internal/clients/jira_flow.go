@@ -0,0 +1,194 @@
+package clients
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JiraFlow holds the flow metrics derived from an issue's changelog.
+type JiraFlow struct {
+	// StartedAt is the first transition into an in-progress status. It is
+	// zero if the issue never passed through one.
+	StartedAt time.Time
+	// CycleTime runs from StartedAt to resolution.
+	CycleTime time.Duration
+	// LeadTime runs from creation to resolution.
+	LeadTime time.Duration
+	// TimeInStatus lists the time spent in each status, in the order the
+	// statuses were first entered.
+	TimeInStatus []StatusDuration
+}
+
+// StatusDuration is the total time an issue spent in one status.
+type StatusDuration struct {
+	Status   string
+	Duration time.Duration
+}
+
+type jiraChangelog struct {
+	StartAt    int                 `json:"startAt"`
+	MaxResults int                 `json:"maxResults"`
+	Total      int                 `json:"total"`
+	Histories  []jiraChangeHistory `json:"histories"`
+}
+
+type jiraChangeHistory struct {
+	Created string `json:"created"`
+	Items   []struct {
+		Field      string `json:"field"`
+		FromString string `json:"fromString"`
+		ToString   string `json:"toString"`
+	} `json:"items"`
+}
+
+// jiraCloudChangelogPage is a page of /rest/api/3/issue/{key}/changelog.
+type jiraCloudChangelogPage struct {
+	StartAt int                 `json:"startAt"`
+	Total   int                 `json:"total"`
+	IsLast  bool                `json:"isLast"`
+	Values  []jiraChangeHistory `json:"values"`
+}
+
+// statusChange is a single status transition taken from the changelog.
+type statusChange struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+// issueFlow computes the flow metrics of a search result. The search embeds
+// at most a page of changelog, so longer histories are fetched separately.
+// badHistory reports whether some changelog timestamps could not be parsed.
+func (j *JiraClient) issueFlow(issue jiraSearchIssue, converted JiraIssue) (flow *JiraFlow, badHistory bool, err error) {
+	var histories []jiraChangeHistory
+	if issue.Changelog != nil {
+		histories = issue.Changelog.Histories
+	}
+	if issue.Changelog == nil || len(histories) < issue.Changelog.Total {
+		histories, err = j.fetchChangelog(issue.Key)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	var changes []statusChange
+	for _, history := range histories {
+		for _, item := range history.Items {
+			if item.Field != "status" {
+				continue
+			}
+			at, err := parseJiraTime(history.Created)
+			if err != nil {
+				badHistory = true
+				continue
+			}
+			changes = append(changes, statusChange{At: at, From: item.FromString, To: item.ToString})
+		}
+	}
+	sort.SliceStable(changes, func(a, b int) bool {
+		return changes[a].At.Before(changes[b].At)
+	})
+
+	return computeFlow(converted, changes, j.opts.InProgressStatuses), badHistory, nil
+}
+
+// computeFlow derives flow metrics from an issue's status transitions.
+func computeFlow(issue JiraIssue, changes []statusChange, inProgressStatuses []string) *JiraFlow {
+	flow := &JiraFlow{}
+
+	for _, change := range changes {
+		if containsFold(inProgressStatuses, change.To) {
+			flow.StartedAt = change.At
+			break
+		}
+	}
+
+	if !issue.Resolved.IsZero() {
+		if !issue.Created.IsZero() {
+			flow.LeadTime = issue.Resolved.Sub(issue.Created)
+		}
+		if !flow.StartedAt.IsZero() && issue.Resolved.After(flow.StartedAt) {
+			flow.CycleTime = issue.Resolved.Sub(flow.StartedAt)
+		}
+	}
+
+	// Walk the transitions, charging the time between them to the status the
+	// issue was in. Time after resolution is not counted.
+	status := issue.Status
+	if len(changes) > 0 {
+		status = changes[0].From
+	}
+	since := issue.Created
+	durations := map[string]time.Duration{}
+	var order []string
+	charge := func(until time.Time) {
+		if since.IsZero() || !until.After(since) {
+			return
+		}
+		if _, seen := durations[status]; !seen {
+			order = append(order, status)
+		}
+		durations[status] += until.Sub(since)
+	}
+	for _, change := range changes {
+		charge(change.At)
+		status = change.To
+		since = change.At
+	}
+	if !issue.Resolved.IsZero() {
+		charge(issue.Resolved)
+	}
+
+	for _, name := range order {
+		flow.TimeInStatus = append(flow.TimeInStatus, StatusDuration{Status: name, Duration: durations[name]})
+	}
+
+	return flow
+}
+
+// fetchChangelog retrieves an issue's full changelog.
+func (j *JiraClient) fetchChangelog(key string) ([]jiraChangeHistory, error) {
+	if j.opts.AuthMode != JiraAuthCloud {
+		var issue struct {
+			Changelog jiraChangelog `json:"changelog"`
+		}
+		params := url.Values{}
+		params.Add("expand", "changelog")
+		params.Add("fields", "status")
+		if err := j.get("/rest/api/2/issue/"+url.PathEscape(key), params, &issue); err != nil {
+			return nil, err
+		}
+		return issue.Changelog.Histories, nil
+	}
+
+	var histories []jiraChangeHistory
+	for {
+		params := url.Values{}
+		params.Add("startAt", strconv.Itoa(len(histories)))
+		params.Add("maxResults", strconv.Itoa(jiraPageSize))
+
+		var page jiraCloudChangelogPage
+		if err := j.get("/rest/api/3/issue/"+url.PathEscape(key)+"/changelog", params, &page); err != nil {
+			return nil, err
+		}
+		histories = append(histories, page.Values...)
+
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			break
+		}
+	}
+
+	return histories, nil
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package clients
+
+import (
+	"log"
+	"net/url"
+	"strings"
+)
+
+// jiraRemoteLinkConcurrency is the number of issues whose remote links are
+// read at the same time.
+const jiraRemoteLinkConcurrency = 4
+
+// jiraRemoteLink is an entry of /rest/api/{2,3}/issue/{key}/remotelink.
+type jiraRemoteLink struct {
+	Object struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	} `json:"object"`
+}
+
+// fetchRemoteLinks fills in the remote link URLs of each issue, such as the
+// pull requests linked by the GitHub integration. Like epic names, the links
+// are best effort: issues whose links cannot be read are left without them
+// and listed in a warning.
+func (j *JiraClient) fetchRemoteLinks(data *JiraData) {
+	failed := make([]bool, len(data.Issues))
+	forEachConcurrently(jiraRemoteLinkConcurrency, len(data.Issues), func(i int) {
+		issue := &data.Issues[i]
+		var links []jiraRemoteLink
+		if err := j.get(j.restAPI()+"/issue/"+url.PathEscape(issue.Key)+"/remotelink", nil, &links); err != nil {
+			log.Printf("    Warning: Error fetching remote links of %s: %v", issue.Key, err)
+			failed[i] = true
+			return
+		}
+		for _, link := range links {
+			if link.Object.URL != "" {
+				issue.RemoteLinks = append(issue.RemoteLinks, link.Object.URL)
+			}
+		}
+	})
+
+	var keys []string
+	for i, issue := range data.Issues {
+		if failed[i] {
+			keys = append(keys, issue.Key)
+		}
+	}
+	if len(keys) > 0 {
+		data.addWarning("Remote links of some Jira issues could not be read, so pull requests linked only that way are not shown: %s.", strings.Join(keys, ", "))
+	}
+}
@@ -0,0 +1,120 @@
+package clients
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JiraWorklog is time logged by the associate on an issue.
+type JiraWorklog struct {
+	IssueKey     string
+	IssueSummary string
+	Project      string
+	Started      time.Time
+	TimeSpent    time.Duration
+}
+
+type jiraWorklog struct {
+	Author           jiraUser `json:"author"`
+	Started          string   `json:"started"`
+	TimeSpentSeconds int      `json:"timeSpentSeconds"`
+}
+
+// jiraWorklogPage is a page of /rest/api/{2,3}/issue/{key}/worklog.
+type jiraWorklogPage struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Worklogs   []jiraWorklog `json:"worklogs"`
+}
+
+// fetchWorklogs collects the time the user logged in the window, whether or
+// not the issues are resolved. The search narrows the issues down, but an
+// issue's worklogs can only be listed in full, so they are filtered here by
+// author and start time.
+func (j *JiraClient) fetchWorklogs(data *JiraData, username string, startDate, endDate time.Time) ([]JiraWorklog, error) {
+	jql := fmt.Sprintf("worklogAuthor = %s AND worklogDate >= %s AND worklogDate <= %s",
+		jqlString(username),
+		jqlString(startDate.Format("2006-01-02")),
+		jqlString(endDate.Format("2006-01-02")),
+	)
+
+	type issueRef struct {
+		key     string
+		summary string
+	}
+	var issues []issueRef
+	_, err := j.searchIssues(j.withExtraJQL(jql)+" ORDER BY key ASC", []string{"summary"}, "", func(issue jiraSearchIssue) {
+		issues = append(issues, issueRef{key: issue.Key, summary: issue.Fields.Summary})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	worklogs := []JiraWorklog{}
+	for _, issue := range issues {
+		entries, err := j.fetchIssueWorklogs(issue.key)
+		if err != nil {
+			return nil, fmt.Errorf("fetching worklogs of %s: %w", issue.key, err)
+		}
+
+		badDate := false
+		for _, entry := range entries {
+			if !entry.Author.is(username) {
+				continue
+			}
+			started, err := parseJiraTime(entry.Started)
+			if err != nil {
+				badDate = true
+				continue
+			}
+			if started.Before(startDate) || started.After(endDate) {
+				continue
+			}
+			worklogs = append(worklogs, JiraWorklog{
+				IssueKey:     issue.key,
+				IssueSummary: issue.summary,
+				Project:      projectKey(issue.key),
+				Started:      started,
+				TimeSpent:    time.Duration(entry.TimeSpentSeconds) * time.Second,
+			})
+		}
+		if badDate {
+			data.addUnparsed(issue.key, []string{"worklog"})
+		}
+	}
+
+	return worklogs, nil
+}
+
+func (j *JiraClient) fetchIssueWorklogs(key string) ([]jiraWorklog, error) {
+	var worklogs []jiraWorklog
+	for {
+		params := url.Values{}
+		params.Add("startAt", strconv.Itoa(len(worklogs)))
+		params.Add("maxResults", strconv.Itoa(jiraPageSize))
+
+		var page jiraWorklogPage
+		if err := j.get(j.restAPI()+"/issue/"+url.PathEscape(key)+"/worklog", params, &page); err != nil {
+			return nil, err
+		}
+		worklogs = append(worklogs, page.Worklogs...)
+
+		if len(page.Worklogs) == 0 || len(worklogs) >= page.Total {
+			break
+		}
+	}
+
+	return worklogs, nil
+}
+
+// projectKey returns the project part of an issue key such as "PROJ-123".
+func projectKey(issueKey string) string {
+	if i := strings.LastIndex(issueKey, "-"); i > 0 {
+		return issueKey[:i]
+	}
+	return issueKey
+}
@@ -9,10 +9,58 @@ import (
 type Config struct {
 	Jira struct {
 		URL   string `yaml:"url"`
-		Token string `yaml:"token"` // Personal Access Token
+		Token string `yaml:"token"` // Personal Access Token, or API token on Jira Cloud
+		// "pat" (default) for Data Center/Server, "cloud" for Jira Cloud
+		AuthMode string `yaml:"auth_mode"`
+		Email    string `yaml:"email"` // Account email, required for Jira Cloud
+		// Custom field holding story points; differs between Jira instances.
+		// Run with --discover-jira-fields to find the right ID.
+		StoryPointsField string `yaml:"story_points_field"`
+		// Workflow statuses that count as done (default: Done, Closed, Resolved)
+		DoneStatuses []string `yaml:"done_statuses"`
+		// Resolutions that are not real work, e.g. Duplicate or Won't Fix
+		ExcludedResolutions []string `yaml:"excluded_resolutions"`
+		// Optional JQL clause ANDed into every search, e.g. a project filter
+		ExtraJQL string `yaml:"extra_jql"`
+		// Fetch changelogs to compute cycle time and time in status
+		FlowMetrics bool `yaml:"flow_metrics"`
+		// Statuses that start the cycle time clock (default: In Progress)
+		InProgressStatuses []string `yaml:"in_progress_statuses"`
+		// Sprint and Epic Link custom fields (see --discover-jira-fields).
+		// Without an epic link field, epics come from the parent field.
+		SprintField   string `yaml:"sprint_field"`
+		EpicLinkField string `yaml:"epic_link_field"`
+		// Read each completed issue's remote links to find the pull
+		// requests linked by the Jira GitHub integration
+		RemoteLinks bool `yaml:"remote_links"`
 	} `yaml:"jira"`
 	GitHub struct {
 		Token string `yaml:"token"` // Personal Access Token
+		// "rest" (default) or "graphql" to fetch pull requests and reviews
+		// through the GraphQL API, which needs far fewer requests
+		Backend string `yaml:"backend"`
+		// GitHub Enterprise Server API URLs, e.g. https://github.example.com/api/v3/
+		BaseURL   string `yaml:"base_url"`
+		UploadURL string `yaml:"upload_url"`
+		// Number of pull requests whose details are fetched at the same
+		// time (default 4)
+		Concurrency int `yaml:"concurrency"`
+		// List each PR's files to break changes down by language and
+		// directory; extra patterns of generated or vendored files
+		FileBreakdown  bool     `yaml:"file_breakdown"`
+		GeneratedPaths []string `yaml:"generated_paths"`
+		// Search commits to report those pushed without a pull request
+		Commits bool `yaml:"commits"`
+		// Organizations and repositories to count activity in, for every
+		// associate
+		Scope RepoScope `yaml:"scope"`
+		// Patterns flagging PRs of bots and automation, added to the
+		// built-in ones
+		Automation struct {
+			Authors []string `yaml:"authors"`
+			Titles  []string `yaml:"titles"`
+			Labels  []string `yaml:"labels"`
+		} `yaml:"automation"`
 	} `yaml:"github"`
 	Associates map[string]AssociateInfo `yaml:"associates"`
 }
@@ -21,6 +69,15 @@ type AssociateInfo struct {
 	JiraUsername   string `yaml:"jira_username"`
 	GitHubUsername string `yaml:"github_username"`
 	FullName       string `yaml:"full_name"`
+	// Organizations and repositories to count activity in, on top of the
+	// global github.scope
+	GitHubScope RepoScope `yaml:"github_scope"`
+}
+
+// RepoScope lists "owner" or "owner/repo" patterns to include or exclude.
+type RepoScope struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
 }
 
 func Load(filename string) (*Config, error) {
@@ -0,0 +1,604 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/acardace/contribution-report/internal/clients"
+)
+
+// FlowStat summarizes Jira flow metrics for one issue type.
+type FlowStat struct {
+	Type        string
+	Issues      int
+	MedianCycle time.Duration
+	P90Cycle    time.Duration
+	MedianLead  time.Duration
+}
+
+// flowStats groups issues with flow metrics by type, ordered by issue count.
+// Issues that never entered an in-progress status have no cycle time and only
+// count towards lead time.
+func flowStats(issues []clients.JiraIssue) []FlowStat {
+	cycles := map[string][]time.Duration{}
+	leads := map[string][]time.Duration{}
+	for _, issue := range issues {
+		if issue.Flow == nil {
+			continue
+		}
+		if issue.Flow.CycleTime > 0 {
+			cycles[issue.Type] = append(cycles[issue.Type], issue.Flow.CycleTime)
+		}
+		if issue.Flow.LeadTime > 0 {
+			leads[issue.Type] = append(leads[issue.Type], issue.Flow.LeadTime)
+		}
+	}
+
+	var stats []FlowStat
+	for issueType, lead := range leads {
+		cycle := cycles[issueType]
+		stats = append(stats, FlowStat{
+			Type:        issueType,
+			Issues:      len(lead),
+			MedianCycle: percentile(cycle, 50),
+			P90Cycle:    percentile(cycle, 90),
+			MedianLead:  percentile(lead, 50),
+		})
+	}
+	sort.Slice(stats, func(a, b int) bool {
+		if stats[a].Issues != stats[b].Issues {
+			return stats[a].Issues > stats[b].Issues
+		}
+		return stats[a].Type < stats[b].Type
+	})
+
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of values, or zero
+// when there are none.
+func percentile(values []time.Duration, p int) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// formatDuration renders a duration in days, or hours when under a day.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+// TimeLogged is the time logged against one issue, project or week.
+type TimeLogged struct {
+	Label   string
+	Summary string
+	Hours   float64
+}
+
+// worklogTotals aggregates worklogs per issue, per project and per week
+// (weeks start on Monday). Issues and projects are ordered by time logged,
+// weeks chronologically.
+func worklogTotals(worklogs []clients.JiraWorklog) (total float64, byIssue, byProject, byWeek []TimeLogged) {
+	issueHours := map[string]float64{}
+	issueSummaries := map[string]string{}
+	projectHours := map[string]float64{}
+	weekHours := map[string]float64{}
+	for _, w := range worklogs {
+		hours := w.TimeSpent.Hours()
+		total += hours
+		issueHours[w.IssueKey] += hours
+		issueSummaries[w.IssueKey] = w.IssueSummary
+		projectHours[w.Project] += hours
+		weekHours[weekStart(w.Started).Format("2006-01-02")] += hours
+	}
+
+	for key, hours := range issueHours {
+		byIssue = append(byIssue, TimeLogged{Label: key, Summary: issueSummaries[key], Hours: hours})
+	}
+	for project, hours := range projectHours {
+		byProject = append(byProject, TimeLogged{Label: project, Hours: hours})
+	}
+	for week, hours := range weekHours {
+		byWeek = append(byWeek, TimeLogged{Label: week, Hours: hours})
+	}
+
+	byHours := func(list []TimeLogged) {
+		sort.Slice(list, func(a, b int) bool {
+			if list[a].Hours != list[b].Hours {
+				return list[a].Hours > list[b].Hours
+			}
+			return list[a].Label < list[b].Label
+		})
+	}
+	byHours(byIssue)
+	byHours(byProject)
+	sort.Slice(byWeek, func(a, b int) bool { return byWeek[a].Label < byWeek[b].Label })
+
+	return total, byIssue, byProject, byWeek
+}
+
+// weekStart returns the Monday starting the week of t.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// EpicGroup is the set of completed issues that belong to one epic. Issues
+// without an epic are collected in a group with an empty Key.
+type EpicGroup struct {
+	Key         string
+	Name        string
+	Issues      []clients.JiraIssue
+	StoryPoints float64
+}
+
+// epicGroups groups issues by epic, largest epics first and issues without
+// an epic last. It returns nil when no issue belongs to an epic.
+func epicGroups(issues []clients.JiraIssue) []EpicGroup {
+	groups := map[string]*EpicGroup{}
+	hasEpic := false
+	for _, issue := range issues {
+		group, ok := groups[issue.EpicKey]
+		if !ok {
+			group = &EpicGroup{Key: issue.EpicKey, Name: issue.EpicName}
+			groups[issue.EpicKey] = group
+		}
+		group.Issues = append(group.Issues, issue)
+		if issue.HasStoryPoints {
+			group.StoryPoints += issue.StoryPoints
+		}
+		if issue.EpicKey != "" {
+			hasEpic = true
+		}
+	}
+	if !hasEpic {
+		return nil
+	}
+
+	result := make([]EpicGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if (result[a].Key == "") != (result[b].Key == "") {
+			return result[b].Key == ""
+		}
+		if len(result[a].Issues) != len(result[b].Issues) {
+			return len(result[a].Issues) > len(result[b].Issues)
+		}
+		return result[a].Key < result[b].Key
+	})
+
+	return result
+}
+
+// sprintCount returns the number of distinct sprints the issues were
+// completed in. The sprint field lists every sprint an unfinished issue was
+// carried over through, oldest first, so only the last one counts.
+func sprintCount(issues []clients.JiraIssue) int {
+	sprints := map[string]bool{}
+	for _, issue := range issues {
+		if len(issue.Sprints) > 0 {
+			sprints[issue.Sprints[len(issue.Sprints)-1]] = true
+		}
+	}
+	return len(sprints)
+}
+
+// WorkArea counts the completed issues and story points in one project or
+// component.
+type WorkArea struct {
+	Name        string
+	Issues      int
+	StoryPoints float64
+}
+
+// workAreas tallies issues under every area returned by areasOf, largest
+// areas first. Issues without an area are counted under noArea.
+func workAreas(issues []clients.JiraIssue, areasOf func(clients.JiraIssue) []string, noArea string) []WorkArea {
+	areas := map[string]*WorkArea{}
+	for _, issue := range issues {
+		names := areasOf(issue)
+		if len(names) == 0 {
+			names = []string{noArea}
+		}
+		for _, name := range names {
+			area, ok := areas[name]
+			if !ok {
+				area = &WorkArea{Name: name}
+				areas[name] = area
+			}
+			area.Issues++
+			if issue.HasStoryPoints {
+				area.StoryPoints += issue.StoryPoints
+			}
+		}
+	}
+
+	result := make([]WorkArea, 0, len(areas))
+	for _, area := range areas {
+		result = append(result, *area)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Issues != result[b].Issues {
+			return result[a].Issues > result[b].Issues
+		}
+		return result[a].Name < result[b].Name
+	})
+
+	return result
+}
+
+func issueProject(issue clients.JiraIssue) []string {
+	if issue.Project == "" {
+		return nil
+	}
+	if issue.ProjectName == "" {
+		return []string{issue.Project}
+	}
+	return []string{issue.ProjectName + " (" + issue.Project + ")"}
+}
+
+func issueComponents(issue clients.JiraIssue) []string {
+	return issue.Components
+}
+
+// ReviewStats summarizes the verdicts and depth of an associate's reviews.
+type ReviewStats struct {
+	// Submitted counts individual reviews; a pull request reviewed in
+	// several rounds counts once per round.
+	Submitted      int
+	Approvals      int
+	ChangeRequests int
+	InlineComments int
+
+	// Turnaround from being requested as a reviewer to the first review,
+	// over the reviews where the request is known
+	Turnarounds      int
+	MedianTurnaround time.Duration
+	P90Turnaround    time.Duration
+}
+
+// CommentsPerReview is the average number of inline comments per submitted
+// review.
+func (s ReviewStats) CommentsPerReview() float64 {
+	if s.Submitted == 0 {
+		return 0
+	}
+	return float64(s.InlineComments) / float64(s.Submitted)
+}
+
+func reviewStats(reviews []clients.CodeReview) ReviewStats {
+	var stats ReviewStats
+	var turnarounds []time.Duration
+	for _, review := range reviews {
+		if turnaround, ok := review.Turnaround(); ok {
+			turnarounds = append(turnarounds, turnaround)
+		}
+		stats.InlineComments += review.InlineComments
+		for _, submission := range review.Reviews {
+			stats.Submitted++
+			switch submission.State {
+			case clients.ReviewApproved:
+				stats.Approvals++
+			case clients.ReviewChangesRequested:
+				stats.ChangeRequests++
+			}
+		}
+	}
+	stats.Turnarounds = len(turnarounds)
+	stats.MedianTurnaround = percentile(turnarounds, 50)
+	stats.P90Turnaround = percentile(turnarounds, 90)
+	return stats
+}
+
+// reviewTurnaround formats the turnaround of a review, or returns "" when it
+// is not known.
+func reviewTurnaround(review clients.CodeReview) string {
+	turnaround, ok := review.Turnaround()
+	if !ok {
+		return ""
+	}
+	return formatDuration(turnaround)
+}
+
+// LifecycleStat summarizes how the associate's pull requests moved through
+// review, overall or for one repository.
+type LifecycleStat struct {
+	Repo              string
+	PRs               int
+	Reviewed          int
+	Merged            int
+	MedianFirstReview time.Duration
+	P90FirstReview    time.Duration
+	MedianMerge       time.Duration
+	P90Merge          time.Duration
+	// AvgReviewRounds averages over the reviewed pull requests.
+	AvgReviewRounds float64
+	ForcePushes     int
+}
+
+// prLifecycles aggregates pull request lifecycle metrics over all pull
+// requests and per repository, busiest repositories first. It returns no
+// repositories when there is only one, as they would repeat the total.
+func prLifecycles(prs []clients.PullRequest) (total LifecycleStat, byRepo []LifecycleStat) {
+	repoPRs := map[string][]clients.PullRequest{}
+	for _, pr := range prs {
+		repoPRs[pr.Repo] = append(repoPRs[pr.Repo], pr)
+	}
+
+	total = lifecycleStat("All repositories", prs)
+	if len(repoPRs) < 2 {
+		return total, nil
+	}
+	for repo, list := range repoPRs {
+		byRepo = append(byRepo, lifecycleStat(repo, list))
+	}
+	sort.Slice(byRepo, func(a, b int) bool {
+		if byRepo[a].PRs != byRepo[b].PRs {
+			return byRepo[a].PRs > byRepo[b].PRs
+		}
+		return byRepo[a].Repo < byRepo[b].Repo
+	})
+
+	return total, byRepo
+}
+
+func lifecycleStat(repo string, prs []clients.PullRequest) LifecycleStat {
+	stat := LifecycleStat{Repo: repo, PRs: len(prs)}
+	var firstReviews, merges []time.Duration
+	rounds := 0
+	for _, pr := range prs {
+		if d, ok := pr.TimeToFirstReview(); ok {
+			firstReviews = append(firstReviews, d)
+			rounds += pr.ReviewRounds
+		}
+		if d, ok := pr.TimeToMerge(); ok {
+			merges = append(merges, d)
+		}
+		stat.ForcePushes += pr.ForcePushes
+	}
+
+	stat.Reviewed = len(firstReviews)
+	stat.Merged = len(merges)
+	stat.MedianFirstReview = percentile(firstReviews, 50)
+	stat.P90FirstReview = percentile(firstReviews, 90)
+	stat.MedianMerge = percentile(merges, 50)
+	stat.P90Merge = percentile(merges, 90)
+	if stat.Reviewed > 0 {
+		stat.AvgReviewRounds = float64(rounds) / float64(stat.Reviewed)
+	}
+
+	return stat
+}
+
+// CodeShare is the number of lines changed in one language or directory.
+type CodeShare struct {
+	Name  string
+	Lines int
+	// Percent is the share of all lines changed outside generated files.
+	Percent float64
+}
+
+// codeBreakdown totals the lines changed per language and per top-level
+// directory of each repository. Generated and vendored files are only
+// counted in generated. ok is false when no pull request has its files
+// listed.
+func codeBreakdown(prs []clients.PullRequest) (byLanguage, byArea []CodeShare, authored, generated int, ok bool) {
+	languageLines := map[string]int{}
+	areaLines := map[string]int{}
+	for _, pr := range prs {
+		for _, file := range pr.Files {
+			ok = true
+			lines := file.Additions + file.Deletions
+			if file.Generated {
+				generated += lines
+				continue
+			}
+			authored += lines
+			languageLines[file.Language] += lines
+			area := pr.Repo + " (root)"
+			if file.Area != "" {
+				area = pr.Repo + "/" + file.Area
+			}
+			areaLines[area] += lines
+		}
+	}
+
+	return codeShares(languageLines, authored), codeShares(areaLines, authored), authored, generated, ok
+}
+
+func codeShares(lines map[string]int, total int) []CodeShare {
+	shares := make([]CodeShare, 0, len(lines))
+	for name, n := range lines {
+		share := CodeShare{Name: name, Lines: n}
+		if total > 0 {
+			share.Percent = 100 * float64(n) / float64(total)
+		}
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(a, b int) bool {
+		if shares[a].Lines != shares[b].Lines {
+			return shares[a].Lines > shares[b].Lines
+		}
+		return shares[a].Name < shares[b].Name
+	})
+	return shares
+}
+
+// timeToFirstReview formats the time a pull request waited for its first
+// review, or returns "" if it was never reviewed.
+func timeToFirstReview(pr clients.PullRequest) string {
+	d, ok := pr.TimeToFirstReview()
+	if !ok {
+		return ""
+	}
+	return formatDuration(d)
+}
+
+// timeToMerge formats the time a pull request took to merge, or returns ""
+// if it was not merged.
+func timeToMerge(pr clients.PullRequest) string {
+	d, ok := pr.TimeToMerge()
+	if !ok {
+		return ""
+	}
+	return formatDuration(d)
+}
+
+// PRGroup counts the pull requests with one label or milestone.
+type PRGroup struct {
+	Name   string
+	PRs    int
+	Merged int
+}
+
+// prGroups tallies pull requests under every group returned by groupsOf,
+// largest groups first and pull requests without a group, counted under
+// none, last. It returns nil when no pull request has a group.
+func prGroups(prs []clients.PullRequest, groupsOf func(clients.PullRequest) []string, none string) []PRGroup {
+	groups := map[string]*PRGroup{}
+	grouped := false
+	for _, pr := range prs {
+		names := groupsOf(pr)
+		if len(names) == 0 {
+			names = []string{none}
+		} else {
+			grouped = true
+		}
+		for _, name := range names {
+			group, ok := groups[name]
+			if !ok {
+				group = &PRGroup{Name: name}
+				groups[name] = group
+			}
+			group.PRs++
+			if pr.State == clients.PRStateMerged {
+				group.Merged++
+			}
+		}
+	}
+	if !grouped {
+		return nil
+	}
+
+	result := make([]PRGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if (result[a].Name == none) != (result[b].Name == none) {
+			return result[b].Name == none
+		}
+		if result[a].PRs != result[b].PRs {
+			return result[a].PRs > result[b].PRs
+		}
+		return result[a].Name < result[b].Name
+	})
+
+	return result
+}
+
+func prLabels(pr clients.PullRequest) []string {
+	return pr.Labels
+}
+
+func prMilestone(pr clients.PullRequest) []string {
+	if pr.Milestone == "" {
+		return nil
+	}
+	return []string{pr.Milestone}
+}
+
+// jiraPullRequests maps the key of each Jira issue to the pull requests that
+// implement it: those mentioning the key in their title, body or branch, and
+// those among the issue's remote links. linked counts the pull requests
+// matched to at least one issue.
+func jiraPullRequests(issues []clients.JiraIssue, prs []clients.PullRequest) (byKey map[string][]clients.PullRequest, linked int) {
+	byKey = map[string][]clients.PullRequest{}
+	for _, pr := range prs {
+		matched := false
+		for _, issue := range issues {
+			if implements(pr, issue) {
+				byKey[issue.Key] = append(byKey[issue.Key], pr)
+				matched = true
+			}
+		}
+		if matched {
+			linked++
+		}
+	}
+	return byKey, linked
+}
+
+func implements(pr clients.PullRequest, issue clients.JiraIssue) bool {
+	for _, key := range pr.JiraKeys {
+		if key == issue.Key {
+			return true
+		}
+	}
+	if pr.URL == "" {
+		return false
+	}
+	for _, link := range issue.RemoteLinks {
+		// Links may point into the pull request, e.g. to ".../pull/12/files"
+		if rest, ok := strings.CutPrefix(link, pr.URL); ok && (rest == "" || strings.ContainsAny(rest[:1], "/#?")) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAutomatedPRs separates the pull requests flagged as automation.
+func splitAutomatedPRs(prs []clients.PullRequest) (kept, automated []clients.PullRequest) {
+	kept = []clients.PullRequest{}
+	for _, pr := range prs {
+		if pr.Automated {
+			automated = append(automated, pr)
+		} else {
+			kept = append(kept, pr)
+		}
+	}
+	return kept, automated
+}
+
+// splitAutomatedReviews separates the reviews of pull requests flagged as
+// automation.
+func splitAutomatedReviews(reviews []clients.CodeReview) (kept, automated []clients.CodeReview) {
+	kept = []clients.CodeReview{}
+	for _, review := range reviews {
+		if review.Automated {
+			automated = append(automated, review)
+		} else {
+			kept = append(kept, review)
+		}
+	}
+	return kept, automated
+}
+
+// reviewState turns a review state such as "CHANGES_REQUESTED" into
+// "Changes requested".
+func reviewState(state string) string {
+	s := strings.ToLower(strings.ReplaceAll(state, "_", " "))
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
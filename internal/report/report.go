@@ -17,25 +17,96 @@ type ReportData struct {
 	EndDate       string
 	GeneratedAt   string
 	JiraURL       string
+	GitHubScope   string
+
+	// Warnings lists data-quality problems the reader should know about,
+	// such as a data source returning fewer results than it reported.
+	Warnings []string
 
 	// Jira Stats
 	JiraIssues      []clients.JiraIssue
 	TotalJiraIssues int
+	// PRsByJiraKey lists the pull requests implementing each Jira issue.
+	PRsByJiraKey map[string][]clients.PullRequest
+	LinkedPRs    int
 
 	// GitHub Stats
-	PullRequests       []clients.PullRequest
-	Issues             []clients.Issue
-	CodeReviews        []clients.CodeReview
-	TotalPRs           int
-	TotalIssues        int
-	TotalCodeReviews   int
-	MergedPRs          int
-	ClosedIssues       int
-	UniqueReposWorked  int
-	TotalCommits       int
-	TotalLinesAdded    int
-	TotalLinesDeleted  int
-	TotalStoryPoints   float64
+	PullRequests  []clients.PullRequest
+	Issues        []clients.Issue
+	CodeReviews   []clients.CodeReview
+	DirectCommits []clients.Commit
+	// ShowCommits is set when commits were searched, so that no direct
+	// commits can be told apart from commits not being fetched.
+	ShowCommits bool
+	// Pull requests and reviews flagged as automation, such as dependency
+	// bumps, which are not counted in any total
+	AutomatedPRs      []clients.PullRequest
+	AutomatedReviews  []clients.CodeReview
+	TotalPRs          int
+	TotalIssues       int
+	TotalCodeReviews  int
+	ReviewStats       ReviewStats
+	MergedPRs         int
+	ClosedUnmergedPRs int
+	DraftPRs          int
+	ClosedIssues      int
+	IssuesAuthored    int
+	IssuesAssigned    int
+	IssuesCommented   int
+	IssuesMentioned   int
+	UniqueReposWorked int
+	TotalCommits      int
+	TotalLinesAdded   int
+	TotalLinesDeleted int
+	TotalStoryPoints  float64
+
+	// Time to first review, time to merge and review rounds of the
+	// associate's pull requests, overall and per repository
+	PRLifecycle       LifecycleStat
+	PRLifecycleByRepo []LifecycleStat
+
+	// Pull requests grouped by label and by milestone
+	PRsByLabel     []PRGroup
+	PRsByMilestone []PRGroup
+
+	// Lines changed per language and directory, only present when the
+	// files of each pull request were listed
+	HasFileBreakdown bool
+	LinesByLanguage  []CodeShare
+	LinesByArea      []CodeShare
+	AuthoredLines    int
+	GeneratedLines   int
+
+	// Jira work areas of the completed issues
+	AreasByProject   []WorkArea
+	AreasByComponent []WorkArea
+
+	// Jira epics and sprints of the completed issues
+	EpicGroups   []EpicGroup
+	TotalSprints int
+
+	// Jira flow metrics, only present when changelogs were fetched
+	FlowStats []FlowStat
+
+	// Jira activity beyond completed issues
+	JiraActivity          []JiraActivitySection
+	TotalReportedIssues   int
+	TotalCommentedIssues  int
+	TotalFormerlyAssigned int
+
+	// Jira worklogs
+	TotalHoursLogged float64
+	HoursByIssue     []TimeLogged
+	HoursByProject   []TimeLogged
+	HoursByWeek      []TimeLogged
+}
+
+// JiraActivitySection lists one category of Jira activity, such as the
+// issues an associate reported.
+type JiraActivitySection struct {
+	Title       string
+	Description string
+	Issues      []clients.JiraIssue
 }
 
 const htmlTemplate = `<!DOCTYPE html>
@@ -144,6 +215,40 @@ const htmlTemplate = `<!DOCTYPE html>
             background-color: #fff3cd;
             color: #856404;
         }
+        .warnings {
+            background-color: #fff3cd;
+            color: #856404;
+            border-left: 4px solid #ffc107;
+            padding: 15px 20px;
+            border-radius: 4px;
+            margin-bottom: 30px;
+        }
+        .warnings ul {
+            margin: 5px 0 0 0;
+            padding-left: 20px;
+        }
+        .data-table {
+            width: 100%;
+            border-collapse: collapse;
+            font-size: 14px;
+        }
+        .data-table th, .data-table td {
+            text-align: left;
+            padding: 8px 10px;
+            border-bottom: 1px solid #eee;
+        }
+        .data-table th {
+            color: #666;
+            font-weight: 600;
+            text-transform: uppercase;
+            font-size: 12px;
+            letter-spacing: 0.5px;
+        }
+        .bar {
+            background: #667eea;
+            height: 10px;
+            border-radius: 3px;
+        }
         .footer {
             text-align: center;
             color: #666;
@@ -165,9 +270,21 @@ const htmlTemplate = `<!DOCTYPE html>
         <h1>Quarterly Connection Report</h1>
         <p><strong>Associate:</strong> {{.AssociateName}}</p>
         <p><strong>Period:</strong> {{.Quarter}} {{.Year}} ({{.StartDate}} to {{.EndDate}})</p>
+        <p><strong>GitHub scope:</strong> {{.GitHubScope}}</p>
         <p><strong>Generated:</strong> {{.GeneratedAt}}</p>
     </div>
 
+    {{if .Warnings}}
+    <div class="warnings">
+        <strong>This report may be incomplete:</strong>
+        <ul>
+        {{range .Warnings}}
+            <li>{{.}}</li>
+        {{end}}
+        </ul>
+    </div>
+    {{end}}
+
     <div class="stats-grid">
         <div class="stat-card">
             <div class="stat-label">Jira Issues Completed</div>
@@ -176,29 +293,72 @@ const htmlTemplate = `<!DOCTYPE html>
             <div class="stat-label">{{printf "%.1f" .TotalStoryPoints}} story points</div>
             {{end}}
         </div>
+        {{if gt .TotalSprints 0}}
+        <div class="stat-card">
+            <div class="stat-label">Sprints</div>
+            <div class="stat-number">{{.TotalSprints}}</div>
+            <div class="stat-label">Delivered in</div>
+        </div>
+        {{end}}
+        {{if gt .TotalHoursLogged 0.0}}
+        <div class="stat-card">
+            <div class="stat-label">Time Logged</div>
+            <div class="stat-number">{{printf "%.1f" .TotalHoursLogged}}h</div>
+            <div class="stat-label">Across {{len .HoursByIssue}} Jira issues</div>
+        </div>
+        {{end}}
+        <div class="stat-card">
+            <div class="stat-label">Other Jira Activity</div>
+            <div class="stat-number">{{.TotalCommentedIssues}}</div>
+            <div class="stat-label">Issues commented on</div>
+            <div class="stat-label">{{.TotalReportedIssues}} reported / {{.TotalFormerlyAssigned}} formerly assigned</div>
+        </div>
         <div class="stat-card">
             <div class="stat-label">Pull Requests</div>
             <div class="stat-number">{{.TotalPRs}}</div>
-            <div class="stat-label">{{.MergedPRs}} merged</div>
+            <div class="stat-label">{{.MergedPRs}} merged / {{.ClosedUnmergedPRs}} closed unmerged</div>
+            {{if gt .LinkedPRs 0}}
+            <div class="stat-label">{{.LinkedPRs}} linked to Jira accomplishments</div>
+            {{end}}
+            {{if gt .DraftPRs 0}}
+            <div class="stat-label">{{.DraftPRs}} open drafts</div>
+            {{end}}
+            {{if .AutomatedPRs}}
+            <div class="stat-label">+{{len .AutomatedPRs}} automated not counted</div>
+            {{end}}
         </div>
         <div class="stat-card">
             <div class="stat-label">Commits</div>
             <div class="stat-number">{{.TotalCommits}}</div>
             <div class="stat-label">Across all PRs</div>
+            {{if .ShowCommits}}
+            <div class="stat-label">+{{len .DirectCommits}} direct commits</div>
+            {{end}}
         </div>
         <div class="stat-card">
             <div class="stat-label">Code Changes</div>
             <div class="stat-number">{{.TotalLinesAdded}}</div>
             <div class="stat-label" style="color: #22863a;">+{{.TotalLinesAdded}} / <span style="color: #cb2431;">-{{.TotalLinesDeleted}}</span></div>
+            {{if .HasFileBreakdown}}
+            <div class="stat-label">{{.AuthoredLines}} lines changed excluding generated files</div>
+            {{end}}
         </div>
         <div class="stat-card">
             <div class="stat-label">Issues</div>
             <div class="stat-number">{{.TotalIssues}}</div>
-            <div class="stat-label">Created or participated</div>
+            <div class="stat-label">{{.IssuesAuthored}} authored / {{.IssuesAssigned}} assigned</div>
+            <div class="stat-label">{{.IssuesCommented}} commented / {{.IssuesMentioned}} mentioned</div>
         </div>
         <div class="stat-card">
             <div class="stat-label">Code Reviews</div>
             <div class="stat-number">{{.TotalCodeReviews}}</div>
+            {{if .AutomatedReviews}}
+            <div class="stat-label">+{{len .AutomatedReviews}} automated not counted</div>
+            {{end}}
+            <div class="stat-label">{{.ReviewStats.Approvals}} approvals / {{.ReviewStats.ChangeRequests}} change requests</div>
+            {{if gt .ReviewStats.Submitted 0}}
+            <div class="stat-label">{{printf "%.1f" .ReviewStats.CommentsPerReview}} inline comments per review</div>
+            {{end}}
         </div>
         <div class="stat-card">
             <div class="stat-label">Repositories</div>
@@ -221,17 +381,224 @@ const htmlTemplate = `<!DOCTYPE html>
                     <span class="badge badge-info">{{.Type}}</span>
                     {{if .Priority}}<span class="badge badge-warning">{{.Priority}}</span>{{end}}
                     {{if .HasStoryPoints}}<span class="badge badge-info">{{printf "%.1f SP" .StoryPoints}}</span>{{end}}
+                    {{if .EpicName}}<span class="badge badge-info">Epic: {{.EpicName}}</span>{{end}}
+                    {{range .Sprints}}<span class="badge badge-info">{{.}}</span>{{end}}
+                    {{range .Components}}<span class="badge badge-warning">{{.}}</span>{{end}}
+                    {{range .Labels}}<span class="badge badge-success">{{.}}</span>{{end}}
+                    {{range .FixVersions}}<span class="badge badge-info">Fix: {{.}}</span>{{end}}
                     {{if not .Resolved.IsZero}}Resolved: {{.Resolved.Format "2006-01-02"}}{{end}}
+                    {{if .Flow}}{{if gt .Flow.CycleTime 0}}<span class="badge badge-info">Cycle: {{duration .Flow.CycleTime}}</span>{{end}}{{end}}
+                </div>
+                {{with index $.PRsByJiraKey .Key}}
+                <div class="item-meta">
+                    Pull requests:
+                    {{range .}}
+                    <a href="{{.URL}}" target="_blank">{{.Repo}}#{{.Number}}</a>
+                    {{if gt .Additions 0}}<span style="color: #22863a;">+{{.Additions}}</span>{{end}}
+                    {{if gt .Deletions 0}}<span style="color: #cb2431;">-{{.Deletions}}</span>{{end}}
+                    {{if .MergedAt}}<span class="badge badge-success">Merged {{.MergedAt.Format "2006-01-02"}}</span>{{else}}<span class="badge badge-warning">{{.State}}</span>{{end}}
+                    {{end}}
+                </div>
+                {{end}}
+                {{if .Flow}}{{if .Flow.TimeInStatus}}
+                <div class="item-meta">
+                    Time in status:{{range $i, $s := .Flow.TimeInStatus}}{{if $i}},{{end}} {{$s.Status}} {{duration $s.Duration}}{{end}}
+                </div>
+                {{end}}{{end}}
+            </li>
+        {{end}}
+        </ul>
+    </div>
+    {{end}}
+
+    {{if .AreasByProject}}
+    <div class="section">
+        <h2>Work Areas</h2>
+        <h3>By Project</h3>
+        <table class="data-table">
+            <tr><th>Project</th><th>Issues</th><th>Story Points</th></tr>
+            {{range .AreasByProject}}
+            <tr><td>{{.Name}}</td><td>{{.Issues}}</td><td>{{printf "%.1f" .StoryPoints}}</td></tr>
+            {{end}}
+        </table>
+        <h3>By Component</h3>
+        <table class="data-table">
+            <tr><th>Component</th><th>Issues</th><th>Story Points</th></tr>
+            {{range .AreasByComponent}}
+            <tr><td>{{.Name}}</td><td>{{.Issues}}</td><td>{{printf "%.1f" .StoryPoints}}</td></tr>
+            {{end}}
+        </table>
+        <p class="item-meta">Issues with several components count towards each of them.</p>
+    </div>
+    {{end}}
+
+    {{if .EpicGroups}}
+    <div class="section">
+        <h2>Work by Epic</h2>
+        <ul class="item-list">
+        {{range .EpicGroups}}
+            <li class="item">
+                <div class="item-title">
+                    {{if .Key}}<a href="{{$.JiraURL}}/browse/{{.Key}}" target="_blank">{{.Key}}</a> - {{.Name}}{{else}}No epic{{end}}:
+                    {{len .Issues}} issues
+                    {{if gt .StoryPoints 0.0}}<span class="badge badge-info">{{printf "%.1f SP" .StoryPoints}}</span>{{end}}
+                </div>
+                <div class="item-meta">
+                    {{range $i, $issue := .Issues}}{{if $i}}, {{end}}<a href="{{$.JiraURL}}/browse/{{$issue.Key}}" target="_blank">{{$issue.Key}}</a>{{end}}
+                </div>
+            </li>
+        {{end}}
+        </ul>
+    </div>
+    {{end}}
+
+    {{range .JiraActivity}}
+    {{if .Issues}}
+    <div class="section">
+        <h2>{{.Title}} ({{len .Issues}})</h2>
+        <p class="item-meta">{{.Description}}</p>
+        <ul class="item-list">
+        {{range .Issues}}
+            <li class="item">
+                <div class="item-title">
+                    <a href="{{$.JiraURL}}/browse/{{.Key}}" target="_blank">{{.Key}}</a> - {{.Summary}}
+                </div>
+                <div class="item-meta">
+                    <span class="badge badge-success">{{.Status}}</span>
+                    <span class="badge badge-info">{{.Type}}</span>
+                    {{if .Priority}}<span class="badge badge-warning">{{.Priority}}</span>{{end}}
+                    {{if gt .UserComments 0}}<span class="badge badge-info">{{.UserComments}} comments</span>{{end}}
+                    {{if .Assignee}}Assignee: {{.Assignee}}{{end}}
                 </div>
             </li>
         {{end}}
         </ul>
     </div>
     {{end}}
+    {{end}}
+
+    {{if .HoursByIssue}}
+    <div class="section">
+        <h2>Time Logged ({{printf "%.1f" .TotalHoursLogged}}h)</h2>
+        <h3>By Project</h3>
+        <table class="data-table">
+            <tr><th>Project</th><th>Hours</th></tr>
+            {{range .HoursByProject}}
+            <tr><td>{{.Label}}</td><td>{{printf "%.1f" .Hours}}</td></tr>
+            {{end}}
+        </table>
+        <h3>By Week</h3>
+        <table class="data-table">
+            <tr><th>Week Starting</th><th>Hours</th></tr>
+            {{range .HoursByWeek}}
+            <tr><td>{{.Label}}</td><td>{{printf "%.1f" .Hours}}</td></tr>
+            {{end}}
+        </table>
+        <h3>By Issue</h3>
+        <table class="data-table">
+            <tr><th>Issue</th><th>Summary</th><th>Hours</th></tr>
+            {{range .HoursByIssue}}
+            <tr><td><a href="{{$.JiraURL}}/browse/{{.Label}}" target="_blank">{{.Label}}</a></td><td>{{.Summary}}</td><td>{{printf "%.1f" .Hours}}</td></tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{if .FlowStats}}
+    <div class="section">
+        <h2>Flow Metrics</h2>
+        <table class="data-table">
+            <tr><th>Issue Type</th><th>Issues</th><th>Median Cycle Time</th><th>P90 Cycle Time</th><th>Median Lead Time</th></tr>
+            {{range .FlowStats}}
+            <tr>
+                <td>{{.Type}}</td>
+                <td>{{.Issues}}</td>
+                <td>{{duration .MedianCycle}}</td>
+                <td>{{duration .P90Cycle}}</td>
+                <td>{{duration .MedianLead}}</td>
+            </tr>
+            {{end}}
+        </table>
+        <p class="item-meta">Cycle time runs from the first move to an in-progress status until resolution; lead time runs from creation until resolution.</p>
+    </div>
+    {{end}}
+
+    {{if .HasFileBreakdown}}
+    <div class="section">
+        <h2>Languages and Areas</h2>
+        <h3>By Language</h3>
+        <table class="data-table">
+            <tr><th>Language</th><th>Lines Changed</th><th></th></tr>
+            {{range .LinesByLanguage}}
+            <tr><td>{{.Name}}</td><td>{{.Lines}} ({{printf "%.0f" .Percent}}%)</td><td style="width: 50%;"><div class="bar" style="width: {{printf "%.1f" .Percent}}%;"></div></td></tr>
+            {{end}}
+        </table>
+        <h3>By Directory</h3>
+        <table class="data-table">
+            <tr><th>Directory</th><th>Lines Changed</th><th></th></tr>
+            {{range .LinesByArea}}
+            <tr><td>{{.Name}}</td><td>{{.Lines}} ({{printf "%.0f" .Percent}}%)</td><td style="width: 50%;"><div class="bar" style="width: {{printf "%.1f" .Percent}}%;"></div></td></tr>
+            {{end}}
+        </table>
+        <p class="item-meta">Lines changed are additions plus deletions in the associate's pull requests. {{.GeneratedLines}} lines in generated, vendored and lock files are not counted.</p>
+    </div>
+    {{end}}
+
+    {{if .PullRequests}}
+    <div class="section">
+        <h2>Pull Request Lifecycle</h2>
+        <table class="data-table">
+            <tr><th>Repository</th><th>PRs</th><th>Median Time to First Review</th><th>P90 Time to First Review</th><th>Median Time to Merge</th><th>P90 Time to Merge</th><th>Avg Review Rounds</th><th>Force Pushes</th></tr>
+            {{range .PRLifecycleByRepo}}
+            <tr>
+                <td>{{.Repo}}</td>
+                <td>{{.PRs}}</td>
+                <td>{{duration .MedianFirstReview}}</td>
+                <td>{{duration .P90FirstReview}}</td>
+                <td>{{duration .MedianMerge}}</td>
+                <td>{{duration .P90Merge}}</td>
+                <td>{{printf "%.1f" .AvgReviewRounds}}</td>
+                <td>{{.ForcePushes}}</td>
+            </tr>
+            {{end}}
+            {{with .PRLifecycle}}
+            <tr>
+                <th>{{.Repo}}</th>
+                <th>{{.PRs}}</th>
+                <th>{{duration .MedianFirstReview}}</th>
+                <th>{{duration .P90FirstReview}}</th>
+                <th>{{duration .MedianMerge}}</th>
+                <th>{{duration .P90Merge}}</th>
+                <th>{{printf "%.1f" .AvgReviewRounds}}</th>
+                <th>{{.ForcePushes}}</th>
+            </tr>
+            {{end}}
+        </table>
+        <p class="item-meta">Times run from creation. Time to first review counts the {{.PRLifecycle.Reviewed}} pull requests reviewed by someone else, time to merge the {{.PRLifecycle.Merged}} merged ones. A review round is a set of reviews with no new commits in between.</p>
+    </div>
+    {{end}}
 
     {{if .PullRequests}}
     <div class="section">
         <h2>Pull Requests ({{.TotalPRs}})</h2>
+        {{if .PRsByLabel}}
+        <h3>By Label</h3>
+        <table class="data-table">
+            <tr><th>Label</th><th>PRs</th><th>Merged</th></tr>
+            {{range .PRsByLabel}}
+            <tr><td>{{.Name}}</td><td>{{.PRs}}</td><td>{{.Merged}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+        {{if .PRsByMilestone}}
+        <h3>By Milestone</h3>
+        <table class="data-table">
+            <tr><th>Milestone</th><th>PRs</th><th>Merged</th></tr>
+            {{range .PRsByMilestone}}
+            <tr><td>{{.Name}}</td><td>{{.PRs}}</td><td>{{.Merged}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
         <ul class="item-list">
         {{range .PullRequests}}
             <li class="item">
@@ -239,18 +606,33 @@ const htmlTemplate = `<!DOCTYPE html>
                     <a href="{{.URL}}" target="_blank">#{{.Number}}</a> - {{.Title}}
                 </div>
                 <div class="item-meta">
-                    {{if .MergedAt}}
-                    <span class="badge badge-success">Merged</span>
+                    {{if eq .State "merged"}}
+                    <span class="badge badge-success">Merged{{if .MergedAt}} {{.MergedAt.Format "2006-01-02"}}{{end}}</span>
+                    {{else if eq .State "closed unmerged"}}
+                    <span class="badge badge-warning">Closed unmerged</span>
+                    {{else if .Draft}}
+                    <span class="badge badge-warning">Draft</span>
                     {{else}}
                     <span class="badge badge-warning">{{.State}}</span>
                     {{end}}
                     <span class="badge badge-info">{{.Repo}}</span>
+                    {{if .Milestone}}<span class="badge badge-info">Milestone: {{.Milestone}}</span>{{end}}
+                    {{range .Labels}}<span class="badge badge-success">{{.}}</span>{{end}}
                     {{if gt .Commits 0}}<span class="badge badge-info">{{.Commits}} commits</span>{{end}}
                     {{if gt .Additions 0}}<span style="color: #22863a;">+{{.Additions}}</span>{{end}}
                     {{if gt .Deletions 0}}<span style="color: #cb2431;">-{{.Deletions}}</span>{{end}}
                     {{if gt .ChangedFiles 0}}<span class="badge badge-info">{{.ChangedFiles}} files</span>{{end}}
+                    {{with timeToFirstReview .}}<span class="badge badge-info">First review: {{.}}</span>{{end}}
+                    {{with timeToMerge .}}<span class="badge badge-info">Merged in: {{.}}</span>{{end}}
+                    {{if gt .ReviewRounds 1}}<span class="badge badge-info">{{.ReviewRounds}} review rounds</span>{{end}}
+                    {{if gt .ForcePushes 0}}<span class="badge badge-warning">{{.ForcePushes}} force pushes</span>{{end}}
                     Created: {{.CreatedAt.Format "2006-01-02"}}
                 </div>
+                {{if .ClosesIssues}}
+                <div class="item-meta">
+                    {{$repo := .Repo}}Resolves:{{range $i, $issue := .ClosesIssues}}{{if $i}},{{end}} <a href="{{$issue.URL}}" target="_blank">{{if ne $issue.Repo $repo}}{{$issue.Repo}}{{end}}#{{$issue.Number}}</a>{{end}}
+                </div>
+                {{end}}
             </li>
         {{end}}
         </ul>
@@ -273,17 +655,23 @@ const htmlTemplate = `<!DOCTYPE html>
                     <span class="badge badge-warning">{{.State}}</span>
                     {{end}}
                     <span class="badge badge-info">{{.Repo}}</span>
+                    {{range .Involvement}}<span class="badge badge-success">{{.}}</span>{{end}}
+                    {{if gt .Comments 0}}<span class="badge badge-info">{{.Comments}} comments</span>{{end}}
                     Created: {{.CreatedAt.Format "2006-01-02"}}
                 </div>
             </li>
         {{end}}
         </ul>
+        <p class="item-meta">An issue is listed once, with every way the associate was involved in it, so the counts per kind can add up to more than the total.</p>
     </div>
     {{end}}
 
     {{if .CodeReviews}}
     <div class="section">
         <h2>Code Reviews ({{.TotalCodeReviews}})</h2>
+        {{if gt .ReviewStats.Turnarounds 0}}
+        <p class="item-meta">Review turnaround, from being requested as a reviewer to the first review: median {{duration .ReviewStats.MedianTurnaround}}, p90 {{duration .ReviewStats.P90Turnaround}} ({{.ReviewStats.Turnarounds}} requests).</p>
+        {{end}}
         <ul class="item-list">
         {{range .CodeReviews}}
             <li class="item">
@@ -292,7 +680,74 @@ const htmlTemplate = `<!DOCTYPE html>
                 </div>
                 <div class="item-meta">
                     <span class="badge badge-info">{{.Repo}}</span>
-                    Reviewed: {{.CreatedAt.Format "2006-01-02"}}
+                    {{range .Reviews}}
+                    {{if eq .State "APPROVED"}}<span class="badge badge-success">Approved {{.SubmittedAt.Format "2006-01-02"}}</span>
+                    {{else if eq .State "CHANGES_REQUESTED"}}<span class="badge badge-warning">Changes requested {{.SubmittedAt.Format "2006-01-02"}}</span>
+                    {{else}}<span class="badge badge-info">{{reviewState .State}} {{.SubmittedAt.Format "2006-01-02"}}</span>
+                    {{end}}
+                    {{else}}
+                    PR created: {{.CreatedAt.Format "2006-01-02"}}
+                    {{end}}
+                    {{with turnaround .}}<span class="badge badge-info">Turnaround: {{.}}</span>{{end}}
+                    {{if gt .InlineComments 0}}<span class="badge badge-info">{{.InlineComments}} inline comments</span>{{end}}
+                    {{if gt .Additions 0}}<span style="color: #22863a;">+{{.Additions}}</span>{{end}}
+                    {{if gt .Deletions 0}}<span style="color: #cb2431;">-{{.Deletions}}</span>{{end}}
+                    {{if gt .ChangedFiles 0}}<span class="badge badge-info">{{.ChangedFiles}} files</span>{{end}}
+                </div>
+            </li>
+        {{end}}
+        </ul>
+    </div>
+    {{end}}
+
+    {{if or .AutomatedPRs .AutomatedReviews}}
+    <div class="section">
+        <h2>Automation ({{len .AutomatedPRs}} PRs, {{len .AutomatedReviews}} reviews)</h2>
+        <p class="item-meta">Pull requests opened by bots or matching the automation rules, such as dependency bumps and backports. They are not counted in any total above.</p>
+        <ul class="item-list">
+        {{range .AutomatedPRs}}
+            <li class="item">
+                <div class="item-title">
+                    <a href="{{.URL}}" target="_blank">#{{.Number}}</a> - {{.Title}}
+                </div>
+                <div class="item-meta">
+                    <span class="badge badge-info">Authored</span>
+                    <span class="badge badge-info">{{.Repo}}</span>
+                    {{range .Labels}}<span class="badge badge-warning">{{.}}</span>{{end}}
+                    Created: {{.CreatedAt.Format "2006-01-02"}}
+                </div>
+            </li>
+        {{end}}
+        {{range .AutomatedReviews}}
+            <li class="item">
+                <div class="item-title">
+                    <a href="{{.URL}}" target="_blank">#{{.PRNumber}}</a> - {{.PRTitle}}
+                </div>
+                <div class="item-meta">
+                    <span class="badge badge-info">Reviewed</span>
+                    <span class="badge badge-info">{{.Repo}}</span>
+                    {{if .PRAuthor}}<span class="badge badge-info">by {{.PRAuthor}}</span>{{end}}
+                    {{range .Labels}}<span class="badge badge-warning">{{.}}</span>{{end}}
+                </div>
+            </li>
+        {{end}}
+        </ul>
+    </div>
+    {{end}}
+
+    {{if .DirectCommits}}
+    <div class="section">
+        <h2>Direct Commits ({{len .DirectCommits}})</h2>
+        <p class="item-meta">Commits that are not part of any pull request, such as commits pushed directly to a branch.</p>
+        <ul class="item-list">
+        {{range .DirectCommits}}
+            <li class="item">
+                <div class="item-title">
+                    <a href="{{.URL}}" target="_blank">{{printf "%.7s" .SHA}}</a> - {{.Message}}
+                </div>
+                <div class="item-meta">
+                    <span class="badge badge-info">{{.Repo}}</span>
+                    Committed: {{.CommittedAt.Format "2006-01-02"}}
                 </div>
             </li>
         {{end}}
@@ -306,15 +761,28 @@ const htmlTemplate = `<!DOCTYPE html>
 </body>
 </html>`
 
-func Generate(associateName, quarter string, year int, startDate, endDate time.Time, jiraURL string, jiraIssues []clients.JiraIssue, githubData *clients.GitHubData) string {
+func Generate(associateName, quarter string, year int, startDate, endDate time.Time, jiraURL string, jiraData *clients.JiraData, githubData *clients.GitHubData) string {
+	// Pull requests and reviews of bots and automation are listed on their
+	// own and left out of every total.
+	prs, automatedPRs := splitAutomatedPRs(githubData.PullRequests)
+	reviews, automatedReviews := splitAutomatedReviews(githubData.CodeReviews)
+
 	// Count merged PRs
 	mergedPRs := 0
+	closedUnmergedPRs := 0
+	draftPRs := 0
 	totalCommits := 0
 	totalLinesAdded := 0
 	totalLinesDeleted := 0
-	for _, pr := range githubData.PullRequests {
-		if pr.MergedAt != nil {
+	for _, pr := range prs {
+		switch pr.State {
+		case clients.PRStateMerged:
 			mergedPRs++
+		case clients.PRStateClosedUnmerged:
+			closedUnmergedPRs++
+		}
+		if pr.Draft && pr.State == clients.PRStateOpen {
+			draftPRs++
 		}
 		totalCommits += pr.Commits
 		totalLinesAdded += pr.Additions
@@ -323,58 +791,172 @@ func Generate(associateName, quarter string, year int, startDate, endDate time.T
 
 	// Count closed issues
 	closedIssues := 0
+	involvement := map[string]int{}
 	for _, issue := range githubData.Issues {
 		if issue.ClosedAt != nil {
 			closedIssues++
 		}
+		for _, kind := range issue.Involvement {
+			involvement[kind]++
+		}
+	}
+
+	var warnings []string
+	if jiraData.Incomplete() {
+		warnings = append(warnings, fmt.Sprintf("Jira returned %d of %d matching issues.", len(jiraData.Issues), jiraData.ServerTotal))
+	}
+	for _, capped := range githubData.CappedSearches {
+		warnings = append(warnings, "GitHub search "+capped+".")
+	}
+	var directCommits []clients.Commit
+	unlinkedCommits := 0
+	for _, commit := range githubData.Commits {
+		if commit.Direct() {
+			directCommits = append(directCommits, commit)
+		} else if commit.EnrichError != nil {
+			unlinkedCommits++
+		}
+	}
+	if unlinkedCommits > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d commits could not be matched to pull requests and are not listed as direct commits.", unlinkedCommits))
+	}
+
+	var unenriched []string
+	for _, pr := range githubData.PullRequests {
+		if pr.EnrichError != nil {
+			unenriched = append(unenriched, pr.URL)
+		}
+	}
+	if len(unenriched) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Details of %d pull requests could not be fetched, so their commits, size, merge state or review history are missing: %s.", len(unenriched), strings.Join(unenriched, ", ")))
+	}
+	var unenrichedReviews []string
+	for _, review := range githubData.CodeReviews {
+		if review.EnrichError != nil {
+			unenrichedReviews = append(unenrichedReviews, review.URL)
+		}
 	}
+	if len(unenrichedReviews) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Review details of %d pull requests could not be fetched, so their verdicts, comments or turnaround are missing: %s.", len(unenrichedReviews), strings.Join(unenrichedReviews, ", ")))
+	}
+	if len(jiraData.UnparsedDates) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Some Jira dates could not be parsed and are not shown: %s.", strings.Join(jiraData.UnparsedDates, ", ")))
+	}
+	warnings = append(warnings, jiraData.Warnings...)
 
 	// Count story points
 	totalStoryPoints := 0.0
-	for _, issue := range jiraIssues {
+	for _, issue := range jiraData.Issues {
 		if issue.HasStoryPoints {
 			totalStoryPoints += issue.StoryPoints
 		}
 	}
 
+	totalHoursLogged, hoursByIssue, hoursByProject, hoursByWeek := worklogTotals(jiraData.Worklogs)
+	prLifecycle, prLifecycleByRepo := prLifecycles(prs)
+	prsByJiraKey, linkedPRs := jiraPullRequests(jiraData.Issues, prs)
+	linesByLanguage, linesByArea, authoredLines, generatedLines, hasFileBreakdown := codeBreakdown(prs)
+
 	// Count unique repositories
 	repoMap := make(map[string]bool)
-	for _, pr := range githubData.PullRequests {
+	for _, pr := range prs {
 		repoMap[pr.Repo] = true
 	}
 	for _, issue := range githubData.Issues {
 		repoMap[issue.Repo] = true
 	}
-	for _, review := range githubData.CodeReviews {
+	for _, review := range reviews {
 		repoMap[review.Repo] = true
 	}
+	for _, commit := range directCommits {
+		repoMap[commit.Repo] = true
+	}
 
 	data := ReportData{
-		AssociateName:      associateName,
-		Quarter:            quarter,
-		Year:               year,
-		StartDate:          startDate.Format("2006-01-02"),
-		EndDate:            endDate.Format("2006-01-02"),
-		GeneratedAt:        time.Now().Format("2006-01-02 15:04:05"),
-		JiraURL:            jiraURL,
-		JiraIssues:         jiraIssues,
-		TotalJiraIssues:    len(jiraIssues),
-		PullRequests:       githubData.PullRequests,
-		Issues:             githubData.Issues,
-		CodeReviews:        githubData.CodeReviews,
-		TotalPRs:           len(githubData.PullRequests),
-		TotalIssues:        len(githubData.Issues),
-		TotalCodeReviews:   len(githubData.CodeReviews),
-		MergedPRs:          mergedPRs,
-		ClosedIssues:       closedIssues,
-		UniqueReposWorked:  len(repoMap),
-		TotalCommits:       totalCommits,
-		TotalLinesAdded:    totalLinesAdded,
-		TotalLinesDeleted:  totalLinesDeleted,
-		TotalStoryPoints:   totalStoryPoints,
+		AssociateName:     associateName,
+		Quarter:           quarter,
+		Year:              year,
+		StartDate:         startDate.Format("2006-01-02"),
+		EndDate:           endDate.Format("2006-01-02"),
+		GeneratedAt:       time.Now().Format("2006-01-02 15:04:05"),
+		JiraURL:           jiraURL,
+		GitHubScope:       githubData.Scope.String(),
+		Warnings:          warnings,
+		JiraIssues:        jiraData.Issues,
+		TotalJiraIssues:   len(jiraData.Issues),
+		PRsByJiraKey:      prsByJiraKey,
+		LinkedPRs:         linkedPRs,
+		PullRequests:      prs,
+		Issues:            githubData.Issues,
+		CodeReviews:       reviews,
+		DirectCommits:     directCommits,
+		ShowCommits:       githubData.Commits != nil,
+		TotalPRs:          len(prs),
+		TotalIssues:       len(githubData.Issues),
+		TotalCodeReviews:  len(reviews),
+		ReviewStats:       reviewStats(reviews),
+		AutomatedPRs:      automatedPRs,
+		AutomatedReviews:  automatedReviews,
+		PRLifecycle:       prLifecycle,
+		PRLifecycleByRepo: prLifecycleByRepo,
+		HasFileBreakdown:  hasFileBreakdown,
+		LinesByLanguage:   linesByLanguage,
+		LinesByArea:       linesByArea,
+		AuthoredLines:     authoredLines,
+		GeneratedLines:    generatedLines,
+		MergedPRs:         mergedPRs,
+		DraftPRs:          draftPRs,
+		PRsByLabel:        prGroups(prs, prLabels, "No label"),
+		PRsByMilestone:    prGroups(prs, prMilestone, "No milestone"),
+		ClosedUnmergedPRs: closedUnmergedPRs,
+		ClosedIssues:      closedIssues,
+		IssuesAuthored:    involvement[clients.IssueAuthored],
+		IssuesAssigned:    involvement[clients.IssueAssigned],
+		IssuesCommented:   involvement[clients.IssueCommented],
+		IssuesMentioned:   involvement[clients.IssueMentioned],
+		UniqueReposWorked: len(repoMap),
+		TotalCommits:      totalCommits,
+		TotalLinesAdded:   totalLinesAdded,
+		TotalLinesDeleted: totalLinesDeleted,
+		TotalStoryPoints:  totalStoryPoints,
+		AreasByProject:    workAreas(jiraData.Issues, issueProject, "No project"),
+		AreasByComponent:  workAreas(jiraData.Issues, issueComponents, "No component"),
+		EpicGroups:        epicGroups(jiraData.Issues),
+		TotalSprints:      sprintCount(jiraData.Issues),
+		FlowStats:         flowStats(jiraData.Issues),
+		JiraActivity: []JiraActivitySection{
+			{
+				Title:       "Jira Issues Reported",
+				Description: "Issues created by the associate during the period.",
+				Issues:      jiraData.ReportedIssues,
+			},
+			{
+				Title:       "Jira Issues Commented On",
+				Description: "Issues the associate commented on during the period.",
+				Issues:      jiraData.CommentedIssues,
+			},
+			{
+				Title:       "Jira Issues Formerly Assigned",
+				Description: "Issues assigned to the associate during the period that are now assigned to someone else or unassigned.",
+				Issues:      jiraData.FormerlyAssignedIssues,
+			},
+		},
+		TotalReportedIssues:   len(jiraData.ReportedIssues),
+		TotalCommentedIssues:  len(jiraData.CommentedIssues),
+		TotalFormerlyAssigned: len(jiraData.FormerlyAssignedIssues),
+		TotalHoursLogged:      totalHoursLogged,
+		HoursByIssue:          hoursByIssue,
+		HoursByProject:        hoursByProject,
+		HoursByWeek:           hoursByWeek,
 	}
 
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+	tmpl, err := template.New("report").Funcs(template.FuncMap{
+		"duration":          formatDuration,
+		"reviewState":       reviewState,
+		"turnaround":        reviewTurnaround,
+		"timeToFirstReview": timeToFirstReview,
+		"timeToMerge":       timeToMerge,
+	}).Parse(htmlTemplate)
 	if err != nil {
 		return fmt.Sprintf("Error parsing template: %v", err)
 	}